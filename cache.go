@@ -0,0 +1,526 @@
+package gohttp
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// XFromCache is the header set on responses served from a Session cache.
+const XFromCache = "X-From-Cache"
+
+const (
+	// maxCacheBody is the maximum size of a response body stored in cache.
+	maxCacheBody = 10 << 20
+	// revalidateTimeout is the timeout of background revalidation.
+	revalidateTimeout = time.Minute
+)
+
+// Cache is a storage of cached responses used by Session.
+type Cache interface {
+	// Get returns the value stored for key and whether it was found.
+	Get(key string) ([]byte, bool)
+	// Set stores value for key.
+	Set(key string, value []byte)
+	// Delete removes the value stored for key.
+	Delete(key string)
+}
+
+var (
+	_ Cache = &MemoryCache{}
+	_ Cache = &DiskCache{}
+)
+
+// MemoryCache is an in-memory Cache which evicts the least recently used
+// entries when it is full.
+type MemoryCache struct {
+	mu    sync.Mutex
+	max   int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type memoryItem struct {
+	key   string
+	value []byte
+}
+
+// NewMemoryCache returns a new MemoryCache holding at most maxEntries entries.
+// Zero means no limit.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{max: maxEntries, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+// Get implements the Get method of the Cache interface.
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*memoryItem).value, true
+	}
+	return nil, false
+}
+
+// Set implements the Set method of the Cache interface.
+func (c *MemoryCache) Set(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*memoryItem).value = value
+		return
+	}
+	c.items[key] = c.ll.PushFront(&memoryItem{key, value})
+	if c.max > 0 && c.ll.Len() > c.max {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*memoryItem).key)
+	}
+}
+
+// Delete implements the Delete method of the Cache interface.
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.ll.Remove(e)
+		delete(c.items, key)
+	}
+}
+
+// DiskCache is a Cache which stores each entry as a file in a directory.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a new DiskCache storing entries in dir, creating it if necessary.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir}, nil
+}
+
+func (c *DiskCache) file(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get implements the Get method of the Cache interface.
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	b, err := os.ReadFile(c.file(key))
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// Set implements the Set method of the Cache interface.
+func (c *DiskCache) Set(key string, value []byte) {
+	writeFileAtomic(c.file(key), 0600, func(w io.Writer) error {
+		_, err := w.Write(value)
+		return err
+	})
+}
+
+// Delete implements the Delete method of the Cache interface.
+func (c *DiskCache) Delete(key string) {
+	os.Remove(c.file(key))
+}
+
+// SetCache sets default client to cache responses in c. Nil means no cache.
+func SetCache(c Cache) {
+	defaultSession.SetCache(c)
+}
+
+// SetCache sets Session to cache responses in c as a private cache
+// following RFC 9111. Nil means no cache.
+func (s *Session) SetCache(c Cache) {
+	if c == nil {
+		s.cache = nil
+	} else {
+		s.cache = &httpCache{store: c, inflight: make(map[string]bool)}
+	}
+}
+
+// cacheEntry is a stored response.
+type cacheEntry struct {
+	Response     []byte            `json:"response"`
+	Vary         map[string]string `json:"vary,omitempty"`
+	RequestTime  time.Time         `json:"requestTime"`
+	ResponseTime time.Time         `json:"responseTime"`
+}
+
+// httpCache is the cache state shared by the requests of a Session.
+type httpCache struct {
+	store Cache
+
+	mu       sync.Mutex
+	inflight map[string]bool
+}
+
+// cacheTransport is an http.RoundTripper serving responses from cache.
+type cacheTransport struct {
+	*httpCache
+	next http.RoundTripper
+}
+
+func cacheKey(req *http.Request) string {
+	return req.Method + " " + req.URL.String()
+}
+
+// partial reports whether req asks for part of a resource or sets
+// preconditions which a stored full response cannot answer.
+func partial(req *http.Request) bool {
+	for _, k := range []string{"Range", "If-Range", "If-Match", "If-Unmodified-Since"} {
+		if req.Header.Get(k) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && req.Method != http.MethodOptions && resp.StatusCode < 400 {
+			// Unsafe methods invalidate stored responses (RFC 9111 section 4.4).
+			t.store.Delete(http.MethodGet + " " + req.URL.String())
+			t.store.Delete(http.MethodHead + " " + req.URL.String())
+		}
+		return resp, err
+	}
+	if partial(req) {
+		return t.next.RoundTrip(req)
+	}
+	reqCC := parseCacheControl(req.Header)
+	if _, ok := reqCC["no-store"]; ok {
+		return t.next.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry, cached := t.load(key, req)
+	if cached == nil {
+		return t.fetch(key, req)
+	}
+
+	now := time.Now()
+	age := entry.age(now, cached.Header)
+	lifetime := entry.freshnessLifetime(cached.Header)
+	respCC := parseCacheControl(cached.Header)
+	_, reqNoCache := reqCC["no-cache"]
+	_, respNoCache := respCC["no-cache"]
+	if req.Header.Get("Pragma") == "no-cache" && req.Header.Get("Cache-Control") == "" {
+		reqNoCache = true
+	}
+	if maxAge, ok := reqCC["max-age"]; ok {
+		if d, err := strconv.Atoi(maxAge); err == nil && time.Duration(d)*time.Second < lifetime {
+			lifetime = time.Duration(d) * time.Second
+		}
+	}
+
+	if !reqNoCache && !respNoCache {
+		if age < lifetime {
+			return serveCached(cached, age), nil
+		}
+		if _, ok := respCC["must-revalidate"]; !ok {
+			if swr, ok := respCC["stale-while-revalidate"]; ok {
+				if d, err := strconv.Atoi(swr); err == nil && age < lifetime+time.Duration(d)*time.Second {
+					t.revalidateInBackground(key, req, cached)
+					return serveCached(cached, age), nil
+				}
+			}
+		}
+	}
+	return t.revalidate(key, req, cached)
+}
+
+// load returns the stored entry and response for key matching req.
+func (t *cacheTransport) load(key string, req *http.Request) (*cacheEntry, *http.Response) {
+	b, ok := t.store.Get(key)
+	if !ok {
+		return nil, nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		t.store.Delete(key)
+		return nil, nil
+	}
+	for k, v := range entry.Vary {
+		if req.Header.Get(k) != v {
+			return nil, nil
+		}
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(entry.Response)), req)
+	if err != nil {
+		t.store.Delete(key)
+		return nil, nil
+	}
+	return &entry, resp
+}
+
+// fetch sends req and stores the response if it is cacheable.
+func (t *cacheTransport) fetch(key string, req *http.Request) (*http.Response, error) {
+	requestTime := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.save(key, req, resp, requestTime)
+	return resp, nil
+}
+
+// revalidate sends req with conditional headers from cached. A 304 response
+// refreshes and returns the cached response.
+func (t *cacheTransport) revalidate(key string, req *http.Request, cached *http.Response) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	if etag := cached.Header.Get("ETag"); etag != "" && r.Header.Get("If-None-Match") == "" {
+		r.Header.Set("If-None-Match", etag)
+	}
+	if lm := cached.Header.Get("Last-Modified"); lm != "" && r.Header.Get("If-Modified-Since") == "" {
+		r.Header.Set("If-Modified-Since", lm)
+	}
+	requestTime := time.Now()
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		cached.Body.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusNotModified || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		cached.Body.Close()
+		t.save(key, req, resp, requestTime)
+		return resp, nil
+	}
+	resp.Body.Close()
+	for k, v := range resp.Header {
+		if k != "Content-Length" && k != "Content-Encoding" && k != "Transfer-Encoding" {
+			cached.Header[k] = v
+		}
+	}
+	cached.Header.Del("Age")
+	cached.Header.Del(XFromCache)
+	// The cached body is in memory, so the refreshed response is stored now.
+	body, err := io.ReadAll(cached.Body)
+	cached.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.put(key, req, storedResponse(cached), body, requestTime)
+	cached.Body = io.NopCloser(bytes.NewReader(body))
+	cached.Header.Set(XFromCache, "1")
+	return cached, nil
+}
+
+func (t *cacheTransport) revalidateInBackground(key string, req *http.Request, cached *http.Response) {
+	t.mu.Lock()
+	if t.inflight[key] {
+		t.mu.Unlock()
+		return
+	}
+	t.inflight[key] = true
+	t.mu.Unlock()
+
+	// The request context may carry tracing and logging state of the
+	// original exchange, so none of it is shared with the revalidation.
+	ctx, cancel := context.WithTimeout(context.Background(), revalidateTimeout)
+	r := req.Clone(ctx)
+	_, stale := t.load(key, r)
+	go func() {
+		defer func() {
+			cancel()
+			t.mu.Lock()
+			delete(t.inflight, key)
+			t.mu.Unlock()
+		}()
+		if stale == nil {
+			return
+		}
+		if resp, err := t.revalidate(key, r, stale); err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}()
+}
+
+// save stores resp for key if it is cacheable. The response is stored once
+// its body is read to the end, unless the body exceeds maxCacheBody.
+func (t *cacheTransport) save(key string, req *http.Request, resp *http.Response, requestTime time.Time) {
+	if !cacheable(req, resp) {
+		return
+	}
+	stored := storedResponse(resp)
+	resp.Body = &cacheBody{ReadCloser: resp.Body, store: func(body []byte) {
+		t.put(key, req, stored, body, requestTime)
+	}}
+}
+
+// storedResponse returns a copy of resp without its body and the headers
+// which must not be replayed from cache.
+func storedResponse(resp *http.Response) *http.Response {
+	stored := *resp
+	stored.Header = resp.Header.Clone()
+	// Cookies are set by the original response only.
+	stored.Header.Del("Set-Cookie")
+	stored.Header.Del("Set-Cookie2")
+	stored.TransferEncoding = nil
+	stored.Trailer = nil
+	return &stored
+}
+
+// put stores resp with body for key.
+func (t *cacheTransport) put(key string, req *http.Request, resp *http.Response, body []byte, requestTime time.Time) {
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	b, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return
+	}
+	entry := cacheEntry{Response: b, RequestTime: requestTime, ResponseTime: time.Now()}
+	for _, v := range resp.Header.Values("Vary") {
+		for k := range strings.SplitSeq(v, ",") {
+			if k = http.CanonicalHeaderKey(strings.TrimSpace(k)); k != "" {
+				if entry.Vary == nil {
+					entry.Vary = make(map[string]string)
+				}
+				entry.Vary[k] = req.Header.Get(k)
+			}
+		}
+	}
+	if b, err := json.Marshal(entry); err == nil {
+		t.store.Set(key, b)
+	}
+}
+
+// cacheBody copies a response body while it is read and stores the copy
+// once the body is read to the end.
+type cacheBody struct {
+	io.ReadCloser
+	buf   bytes.Buffer
+	store func([]byte)
+	done  bool
+}
+
+func (b *cacheBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.done {
+		return n, err
+	}
+	if b.buf.Len()+n > maxCacheBody {
+		b.done = true
+		b.buf = bytes.Buffer{}
+		return n, err
+	}
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.done = true
+		b.store(b.buf.Bytes())
+	}
+	return n, err
+}
+
+func serveCached(resp *http.Response, age time.Duration) *http.Response {
+	resp.Header.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	resp.Header.Set(XFromCache, "1")
+	return resp
+}
+
+// age returns the current age of the stored response (RFC 9111 section 4.2.3).
+func (e *cacheEntry) age(now time.Time, header http.Header) time.Duration {
+	var age time.Duration
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		age = max(e.ResponseTime.Sub(date), 0)
+	}
+	if n, err := strconv.Atoi(header.Get("Age")); err == nil {
+		age = max(age, time.Duration(n)*time.Second+e.ResponseTime.Sub(e.RequestTime))
+	}
+	return age + now.Sub(e.ResponseTime)
+}
+
+// freshnessLifetime returns the freshness lifetime of the stored response
+// with header (RFC 9111 section 4.2.1).
+func (e *cacheEntry) freshnessLifetime(header http.Header) time.Duration {
+	cc := parseCacheControl(header)
+	if maxAge, ok := cc["max-age"]; ok {
+		if n, err := strconv.Atoi(maxAge); err == nil {
+			return time.Duration(n) * time.Second
+		}
+		return 0
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		// The time the response was received stands in for a missing Date.
+		date = e.ResponseTime
+	}
+	if expires := header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		return t.Sub(date)
+	}
+	if lm, err := http.ParseTime(header.Get("Last-Modified")); err == nil && date.After(lm) {
+		// Heuristic freshness (RFC 9111 section 4.2.2).
+		return min(date.Sub(lm)/10, 24*time.Hour)
+	}
+	return 0
+}
+
+func cacheable(req *http.Request, resp *http.Response) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	switch resp.StatusCode {
+	case 200, 203, 204, 300, 301, 308, 404, 405, 410, 414, 501:
+	default:
+		return false
+	}
+	if _, ok := parseCacheControl(req.Header)["no-store"]; ok {
+		return false
+	}
+	cc := parseCacheControl(resp.Header)
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	for _, v := range resp.Header.Values("Vary") {
+		if strings.TrimSpace(v) == "*" {
+			return false
+		}
+	}
+	_, maxAge := cc["max-age"]
+	_, noCache := cc["no-cache"]
+	return maxAge || noCache ||
+		resp.Header.Get("Expires") != "" ||
+		resp.Header.Get("ETag") != "" ||
+		resp.Header.Get("Last-Modified") != ""
+}
+
+// parseCacheControl parses Cache-Control header directives.
+func parseCacheControl(header http.Header) map[string]string {
+	cc := make(map[string]string)
+	for _, v := range header.Values("Cache-Control") {
+		for part := range strings.SplitSeq(v, ",") {
+			k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if k != "" {
+				cc[strings.ToLower(k)] = strings.Trim(v, `"`)
+			}
+		}
+	}
+	return cc
+}
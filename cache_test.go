@@ -0,0 +1,257 @@
+package gohttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	var n atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := n.Add(1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "X-Lang")
+			fmt.Fprint(w, r.Header.Get("X-Lang"))
+			return
+		case "/swr":
+			w.Header().Set("Cache-Control", "max-age=0, stale-while-revalidate=60")
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		fmt.Fprint(w, count)
+	}))
+	defer ts.Close()
+
+	get := func(s *Session, path string, headers H) (string, bool) {
+		t.Helper()
+		resp, err := s.Get(ts.URL+path, headers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expected status %d; got %d", http.StatusOK, resp.StatusCode)
+		}
+		return resp.String(), resp.Header.Get(XFromCache) == "1"
+	}
+
+	dir := t.TempDir()
+	disk, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cache := range []Cache{NewMemoryCache(10), disk} {
+		n.Store(0)
+		s := NewSession()
+		s.SetCache(cache)
+
+		if body, hit := get(s, "/fresh", nil); body != "1" || hit {
+			t.Errorf("expected fresh response %q; got %q (cache %t)", "1", body, hit)
+		}
+		if body, hit := get(s, "/fresh", nil); body != "1" || !hit {
+			t.Errorf("expected cached response %q; got %q (cache %t)", "1", body, hit)
+		}
+		if body, hit := get(s, "/fresh", H{"Cache-Control": "no-cache"}); body != "2" || hit {
+			t.Errorf("expected revalidated response %q; got %q (cache %t)", "2", body, hit)
+		}
+
+		if body, hit := get(s, "/etag", nil); body != "3" || hit {
+			t.Errorf("expected response %q; got %q (cache %t)", "3", body, hit)
+		}
+		if body, hit := get(s, "/etag", nil); body != "3" || !hit {
+			t.Errorf("expected not modified cached response %q; got %q (cache %t)", "3", body, hit)
+		}
+		if c := n.Load(); c != 4 {
+			t.Errorf("expected %d requests; got %d", 4, c)
+		}
+
+		if body, _ := get(s, "/vary", H{"X-Lang": "en"}); body != "en" {
+			t.Errorf("expected response %q; got %q", "en", body)
+		}
+		if body, hit := get(s, "/vary", H{"X-Lang": "fr"}); body != "fr" || hit {
+			t.Errorf("expected response %q; got %q (cache %t)", "fr", body, hit)
+		}
+		if body, hit := get(s, "/vary", H{"X-Lang": "fr"}); body != "fr" || !hit {
+			t.Errorf("expected cached response %q; got %q (cache %t)", "fr", body, hit)
+		}
+
+		n.Store(0)
+		if body, hit := get(s, "/swr", nil); body != "1" || hit {
+			t.Errorf("expected response %q; got %q (cache %t)", "1", body, hit)
+		}
+		if body, hit := get(s, "/swr", nil); body != "1" || !hit {
+			t.Errorf("expected stale response %q; got %q (cache %t)", "1", body, hit)
+		}
+		for i := 0; i < 100 && n.Load() != 2; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if c := n.Load(); c != 2 {
+			t.Errorf("expected background revalidation; got %d requests", c)
+		}
+		for i := 0; i < 100; i++ {
+			if body, _ := get(s, "/swr", nil); body == "2" {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		n.Store(0)
+		get(s, "/nostore", nil)
+		if _, hit := get(s, "/nostore", nil); hit {
+			t.Error("expected no-store response not cached")
+		}
+		get(s, "/fresh", nil)
+		if _, err := s.Post(ts.URL+"/fresh", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if _, hit := get(s, "/fresh", nil); hit {
+			t.Error("expected POST invalidates cached response")
+		}
+	}
+}
+
+func TestCacheStoredResponse(t *testing.T) {
+	var n atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Content-Type", "application/octet-stream")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: fmt.Sprint(n.Add(1))})
+		fmt.Fprint(w, "ok")
+	}))
+	defer ts.Close()
+
+	s := NewSession()
+	s.SetCache(NewMemoryCache(10))
+	resp, err := s.R().URL(ts.URL).Stream(true).Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+	if c := n.Load(); c != 1 {
+		t.Errorf("expected %d requests; got %d", 1, c)
+	}
+	resp, err = s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header.Get(XFromCache) == "1" {
+		t.Error("expected response with unread body not cached")
+	}
+	if body := resp.String(); body != "ok" {
+		t.Errorf("expected response %q; got %q", "ok", body)
+	}
+
+	resp, err = s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header.Get(XFromCache) != "1" {
+		t.Error("expected cached response")
+	}
+	if v := resp.Header.Get("Set-Cookie"); v != "" {
+		t.Errorf("expected no Set-Cookie from cache; got %q", v)
+	}
+	u, _ := url.Parse(ts.URL)
+	s.Jar().Clear()
+	s.Get(ts.URL, nil)
+	if cookies := s.Cookies(u); len(cookies) != 0 {
+		t.Errorf("expected no cookies set from cache; got %v", cookies)
+	}
+}
+
+func TestCacheRange(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+	}))
+	defer ts.Close()
+
+	s := NewSession()
+	s.SetCache(NewMemoryCache(10))
+	resp, err := s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(resp.Bytes()); n != len(content) {
+		t.Errorf("expected %d bytes; got %d", len(content), n)
+	}
+	resp, err = s.Get(ts.URL, H{"Range": "bytes=0-9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Errorf("expected status %d; got %d", http.StatusPartialContent, resp.StatusCode)
+	}
+	if body := resp.String(); body != content[:10] {
+		t.Errorf("expected %q; got %q", content[:10], body)
+	}
+	if resp.Header.Get(XFromCache) == "1" {
+		t.Error("expected range request not served from cache")
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if _, err := s.Download(ts.URL, file, &DownloadOptions{Segments: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(file); string(b) != content {
+		t.Error("downloaded content mismatch")
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(2)
+	c.Set("a", []byte("1"))
+	c.Set("b", []byte("2"))
+	c.Get("a")
+	c.Set("c", []byte("3"))
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected least recently used entry evicted")
+	}
+	if v, ok := c.Get("a"); !ok || string(v) != "1" {
+		t.Errorf("expected %q; got %q", "1", v)
+	}
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected entry deleted")
+	}
+}
+
+func TestFreshnessLifetime(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	entry := &cacheEntry{ResponseTime: now.Add(-30 * time.Second)}
+	for _, tc := range []struct {
+		header http.Header
+		expect time.Duration
+	}{
+		{http.Header{"Cache-Control": {"public, max-age=30"}}, 30 * time.Second},
+		{http.Header{"Date": {now.Format(http.TimeFormat)}, "Expires": {now.Add(time.Minute).Format(http.TimeFormat)}}, time.Minute},
+		{http.Header{"Date": {now.Format(http.TimeFormat)}, "Expires": {"0"}}, 0},
+		{http.Header{"Date": {now.Format(http.TimeFormat)}, "Last-Modified": {now.Add(-10 * time.Hour).Format(http.TimeFormat)}}, time.Hour},
+		{http.Header{"Expires": {now.Add(30 * time.Second).Format(http.TimeFormat)}}, time.Minute},
+		{http.Header{}, 0},
+	} {
+		if d := entry.freshnessLifetime(tc.header); d != tc.expect {
+			t.Errorf("%v: expected %v; got %v", tc.header, tc.expect, d)
+		}
+	}
+}
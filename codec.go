@@ -0,0 +1,239 @@
+package gohttp
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Codec encodes and decodes values in a media type. Codecs registered on a
+// Session encode request bodies and decode responses with Response.Decode.
+type Codec interface {
+	// Encode writes the encoding of v to w.
+	Encode(w io.Writer, v any) error
+	// Decode reads the encoded value from r and stores it in v.
+	Decode(r io.Reader, v any) error
+}
+
+// JSONCodec encodes values as JSON.
+type JSONCodec struct{}
+
+// Encode writes the JSON encoding of v to w.
+func (JSONCodec) Encode(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Decode reads the next JSON-encoded value from r and stores it in v.
+func (JSONCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// XMLCodec encodes values as XML.
+type XMLCodec struct{}
+
+// Encode writes the XML encoding of v to w.
+func (XMLCodec) Encode(w io.Writer, v any) error {
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// Decode reads the next XML-encoded value from r and stores it in v.
+func (XMLCodec) Decode(r io.Reader, v any) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+// FormCodec encodes url.Values, map[string]string and map[string][]string
+// as URL-encoded form data.
+type FormCodec struct{}
+
+// Encode writes v, which is url.Values, map[string][]string or
+// map[string]string, to w as URL-encoded form data.
+func (FormCodec) Encode(w io.Writer, v any) error {
+	var values url.Values
+	switch v := v.(type) {
+	case url.Values:
+		values = v
+	case map[string][]string:
+		values = v
+	case map[string]string:
+		values = make(url.Values)
+		for k, s := range v {
+			values.Set(k, s)
+		}
+	default:
+		return fmt.Errorf("form codec: unsupported type %T", v)
+	}
+	_, err := io.WriteString(w, values.Encode())
+	return err
+}
+
+// Decode parses URL-encoded form data from r into v, which is a pointer to
+// url.Values, map[string][]string or map[string]string.
+func (FormCodec) Decode(r io.Reader, v any) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(b))
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *url.Values:
+		*v = values
+	case *map[string][]string:
+		*v = values
+	case *map[string]string:
+		*v = make(map[string]string)
+		for k := range values {
+			(*v)[k] = values.Get(k)
+		}
+	default:
+		return fmt.Errorf("form codec: unsupported type %T", v)
+	}
+	return nil
+}
+
+// TextCodec encodes strings, byte slices and values formatted by fmt as text.
+type TextCodec struct{}
+
+// Encode writes v to w as text.
+func (TextCodec) Encode(w io.Writer, v any) (err error) {
+	switch v := v.(type) {
+	case string:
+		_, err = io.WriteString(w, v)
+	case []byte:
+		_, err = w.Write(v)
+	default:
+		_, err = fmt.Fprint(w, v)
+	}
+	return
+}
+
+// Decode reads r to the end into v, which is a *string or *[]byte.
+func (TextCodec) Decode(r io.Reader, v any) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *string:
+		*v = string(b)
+	case *[]byte:
+		*v = b
+	default:
+		return fmt.Errorf("text codec: unsupported type %T", v)
+	}
+	return nil
+}
+
+var defaultCodecs = map[string]Codec{
+	"application/json":                  JSONCodec{},
+	"application/xml":                   XMLCodec{},
+	"text/xml":                          XMLCodec{},
+	"application/x-www-form-urlencoded": FormCodec{},
+	"text/plain":                        TextCodec{},
+}
+
+// defaultBodyTypes maps Go types to the media type of their request body.
+// Other types are encoded as JSON.
+var defaultBodyTypes = map[reflect.Type]string{
+	reflect.TypeFor[url.Values](): "application/x-www-form-urlencoded",
+}
+
+// RegisterCodec registers the codec of a media type for default session.
+func RegisterCodec(mediaType string, c Codec) {
+	defaultSession.RegisterCodec(mediaType, c)
+}
+
+// RegisterCodec registers the codec of a media type for Session, replacing
+// any existing one. Nil c removes the media type.
+func (s *Session) RegisterCodec(mediaType string, c Codec) {
+	mediaType = strings.ToLower(mediaType)
+	codecs := maps.Clone(s.codecs)
+	if codecs == nil {
+		codecs = maps.Clone(defaultCodecs)
+	}
+	if c == nil {
+		delete(codecs, mediaType)
+	} else {
+		codecs[mediaType] = c
+	}
+	s.codecs = codecs
+}
+
+// RegisterBodyType registers the media type of request bodies of the type of v for default session.
+func RegisterBodyType(v any, mediaType string) {
+	defaultSession.RegisterBodyType(v, mediaType)
+}
+
+// RegisterBodyType registers the media type used to encode request bodies
+// of the type of v for Session. Empty mediaType removes the type.
+func (s *Session) RegisterBodyType(v any, mediaType string) {
+	t := reflect.TypeOf(v)
+	bodyTypes := maps.Clone(s.bodyTypes)
+	if bodyTypes == nil {
+		bodyTypes = maps.Clone(defaultBodyTypes)
+	}
+	if mediaType == "" {
+		delete(bodyTypes, t)
+	} else {
+		bodyTypes[t] = strings.ToLower(mediaType)
+	}
+	s.bodyTypes = bodyTypes
+}
+
+// lookupCodec returns the codec for the media type of contentType.
+// Media types with a +json or +xml structured syntax suffix fall back
+// to the JSON or XML codec.
+func lookupCodec(codecs map[string]Codec, contentType string) Codec {
+	if codecs == nil {
+		codecs = defaultCodecs
+	}
+	mediatype, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	if c, ok := codecs[mediatype]; ok {
+		return c
+	}
+	switch {
+	case strings.HasSuffix(mediatype, "+json"):
+		return codecs["application/json"]
+	case strings.HasSuffix(mediatype, "+xml"):
+		return codecs["application/xml"]
+	}
+	return nil
+}
+
+// bodyCodec returns the codec and media type used to encode data as a
+// request body. A codec registered for contentType, the Content-Type header
+// of the request, takes precedence over the one for the type of data,
+// which is encoded as JSON if none is registered.
+func (s *Session) bodyCodec(data any, contentType string) (Codec, string) {
+	if contentType != "" {
+		if c := lookupCodec(s.codecs, contentType); c != nil {
+			return c, contentType
+		}
+	}
+	bodyTypes := s.bodyTypes
+	if bodyTypes == nil {
+		bodyTypes = defaultBodyTypes
+	}
+	if mediaType, ok := bodyTypes[reflect.TypeOf(data)]; ok {
+		if c := lookupCodec(s.codecs, mediaType); c != nil {
+			return c, mediaType
+		}
+	}
+	return JSONCodec{}, "application/json"
+}
@@ -0,0 +1,189 @@
+package gohttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type csvCodec struct{}
+
+func (csvCodec) Encode(w io.Writer, v any) error {
+	rows, ok := v.([][]string)
+	if !ok {
+		return fmt.Errorf("csv codec: unsupported type %T", v)
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(w, strings.Join(row, ",")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (csvCodec) Decode(r io.Reader, v any) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	rows, ok := v.(*[][]string)
+	if !ok {
+		return fmt.Errorf("csv codec: unsupported type %T", v)
+	}
+	for line := range strings.Lines(string(b)) {
+		*rows = append(*rows, strings.Split(strings.TrimSuffix(line, "\n"), ","))
+	}
+	return nil
+}
+
+func TestCodec(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s\n%s", r.Header.Get("Content-Type"), b)
+	}))
+	defer ts.Close()
+
+	type point struct {
+		X int `json:"x" xml:"x"`
+		Y int `json:"y" xml:"y"`
+	}
+	s := NewSession()
+	s.RegisterCodec("text/csv", csvCodec{})
+	s.RegisterBodyType([][]string(nil), "text/csv")
+	for i, tc := range []struct {
+		header H
+		data   any
+		expect string
+	}{
+		{nil, point{1, 2}, "application/json\n{\"x\":1,\"y\":2}"},
+		{nil, url.Values{"a": {"1"}}, "application/x-www-form-urlencoded\na=1"},
+		{H{"Content-Type": "application/x-www-form-urlencoded"}, map[string]string{"a": "1"}, "application/x-www-form-urlencoded\na=1"},
+		{H{"Content-Type": "application/xml"}, point{1, 2}, "application/xml\n<point><x>1</x><y>2</y></point>"},
+		{H{"Content-Type": "application/atom+xml"}, point{1, 2}, "application/atom+xml\n<point><x>1</x><y>2</y></point>"},
+		{H{"Content-Type": "text/plain; charset=utf-8"}, "hello", "text/plain; charset=utf-8\nhello"},
+		{H{"Content-Type": "application/octet-stream"}, point{1, 2}, "application/octet-stream\n{\"x\":1,\"y\":2}"},
+		{nil, [][]string{{"a", "b"}, {"1", "2"}}, "text/csv\na,b\n1,2\n"},
+		{nil, strings.NewReader("raw"), "\nraw"},
+	} {
+		resp, err := s.Post(ts.URL, tc.header, tc.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := resp.String(); s != tc.expect {
+			t.Errorf("#%d: expected %q; got %q", i, tc.expect, s)
+		}
+	}
+
+	if _, err := s.R().Method("POST").URL(ts.URL).Header("Content-Type", "text/csv").Body(point{}).Do(context.Background()); err == nil {
+		t.Error("gave nil error; want error")
+	}
+
+	s.RegisterBodyType([][]string(nil), "")
+	s.RegisterCodec("text/csv", nil)
+	resp, err := s.Post(ts.URL, nil, [][]string{{"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, expect := resp.String(), "application/json\n[[\"a\"]]"; s != expect {
+		t.Errorf("expected %q; got %q", expect, s)
+	}
+
+	s.RegisterCodec("application/json", csvCodec{})
+	resp, err = s.R().Method("POST").URL(ts.URL).JSON([][]string{{"a", "b"}}).Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, expect := resp.String(), "application/json\na,b\n"; s != expect {
+		t.Errorf("expected %q; got %q", expect, s)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"x":1,"y":2}`))
+		case "/xml":
+			w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+			w.Write([]byte(`<point><x>1</x><y>2</y></point>`))
+		case "/form":
+			w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+			w.Write([]byte(`a=1&b=2`))
+		case "/csv":
+			w.Header().Set("Content-Type", "text/csv")
+			w.Write([]byte("a,b\n1,2\n"))
+		}
+	}))
+	defer ts.Close()
+
+	type point struct {
+		X int `json:"x" xml:"x"`
+		Y int `json:"y" xml:"y"`
+	}
+	s := NewSession()
+	for _, path := range []string{"/json", "/xml"} {
+		resp, err := s.Get(ts.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p point
+		if err := resp.Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		if expect := (point{1, 2}); p != expect {
+			t.Errorf("%s: expected %v; got %v", path, expect, p)
+		}
+	}
+
+	resp, err := s.Get(ts.URL+"/xml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p point
+	if err := resp.XML(&p); err != nil {
+		t.Fatal(err)
+	}
+	if expect := (point{1, 2}); p != expect {
+		t.Errorf("expected %v; got %v", expect, p)
+	}
+
+	resp, err = s.Get(ts.URL+"/form", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]string
+	if err := resp.Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	if m["a"] != "1" || m["b"] != "2" {
+		t.Errorf("unexpected form values: %v", m)
+	}
+
+	resp, err = s.Get(ts.URL+"/csv", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows [][]string
+	if err := resp.Decode(&rows); err == nil {
+		t.Error("gave nil error; want error")
+	}
+	s.RegisterCodec("text/csv", csvCodec{})
+	s.SetStream(true)
+	resp, err = s.Get(ts.URL+"/csv", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if err := resp.Decode(&rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1][1] != "2" {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+}
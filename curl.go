@@ -0,0 +1,512 @@
+package gohttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Curl returns a curl command line equivalent to sending req using default session.
+func Curl(req *http.Request) (string, error) {
+	return defaultSession.Curl(req)
+}
+
+// Curl returns a curl command line equivalent to sending req with Session,
+// including the default and Session headers and the cookies of its jar.
+// If req.GetBody is nil, the body of req is read and replaced.
+func (s *Session) Curl(req *http.Request) (string, error) {
+	body, err := requestBody(req)
+	if err != nil {
+		return "", err
+	}
+	req = s.prepare(req, s.options())
+	if jar := s.client.Jar; jar != nil {
+		for _, c := range jar.Cookies(req.URL) {
+			req.AddCookie(c)
+		}
+	}
+
+	args := []string{"curl"}
+	switch {
+	case req.Method == "HEAD":
+		args = append(args, "--head")
+	case req.Method == "GET" && body == nil, req.Method == "POST" && body != nil:
+	default:
+		args = append(args, "-X", req.Method)
+	}
+	args = append(args, shellQuote(req.URL.String()))
+	if req.Host != "" && req.Host != req.URL.Host {
+		args = append(args, "-H", shellQuote("Host: "+req.Host))
+	}
+	var compressed bool
+	for _, k := range slices.Sorted(maps.Keys(req.Header)) {
+		if k == "Accept-Encoding" && req.Header.Get(k) != "identity" {
+			compressed = true
+			continue
+		}
+		for _, v := range req.Header[k] {
+			args = append(args, "-H", shellQuote(k+": "+v))
+		}
+	}
+	if body != nil {
+		args = append(args, "--data-raw", shellQuote(string(body)))
+	}
+	if compressed {
+		args = append(args, "--compressed")
+	}
+	return strings.Join(args, " "), nil
+}
+
+// Curl returns a curl command line equivalent to sending the request.
+func (r *Request) Curl() (string, error) {
+	if r.err != nil {
+		return "", r.err
+	}
+	req, _, err := r.build(context.Background())
+	if err != nil {
+		return "", err
+	}
+	return r.s.Curl(req)
+}
+
+// requestBody returns the body of req, or nil if it has none.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// shellQuote quotes s as a single POSIX shell word, using ANSI-C quoting
+// for control characters and invalid UTF-8.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_./:=@%+,", r)))
+	}) == -1 {
+		return s
+	}
+	if utf8.ValidString(s) && strings.IndexFunc(s, unicode.IsControl) == -1 {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+	var b strings.Builder
+	b.WriteString("$'")
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\\', r == '\'':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&b, `\x%02x`, r)
+		default:
+			b.WriteRune(r)
+		}
+		i += size
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// ParseCurl parses a curl command line into a Request using default session.
+func ParseCurl(cmd string) (*Request, error) {
+	return defaultSession.ParseCurl(cmd)
+}
+
+// ParseCurl parses a curl command line, such as one copied from browser
+// devtools, into a Request bound to the Session. The method, URL, headers,
+// cookies, data, multipart forms, basic auth and timeout are supported.
+// Options which only affect the output of curl are ignored, and options
+// configuring the connection, such as --insecure, are left to the Session.
+func (s *Session) ParseCurl(cmd string) (_ *Request, err error) {
+	args, err := splitShell(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, errors.New("curl: not a curl command")
+	}
+
+	var method, rawURL string
+	var head, get bool
+	var data []string
+	var timeout time.Duration
+	var user *string
+	header := make(http.Header)
+	params := make(map[string]string)
+	var files []*File
+	defer func() {
+		if err != nil {
+			for _, f := range files {
+				f.Close()
+			}
+		}
+	}()
+	for i := 1; i < len(args); i++ {
+		name, value, hasValue := args[i], "", false
+		if strings.HasPrefix(name, "-") && !strings.HasPrefix(name, "--") && len(name) > 2 {
+			if curlOptions[name[:2]] {
+				name, value, hasValue = name[:2], name[2:], true
+			} else {
+				// Combined flags such as -sSL.
+				for _, c := range name[1:] {
+					if takesValue, ok := curlOptions["-"+string(c)]; !ok || takesValue {
+						return nil, fmt.Errorf("curl: unsupported option %q", name)
+					}
+					if c == 'I' {
+						head = true
+					} else if c == 'G' {
+						get = true
+					}
+				}
+				continue
+			}
+		}
+		if !strings.HasPrefix(name, "-") {
+			rawURL = name
+			continue
+		}
+		takesValue, ok := curlOptions[name]
+		if !ok {
+			return nil, fmt.Errorf("curl: unsupported option %q", name)
+		}
+		if takesValue && !hasValue {
+			if i++; i == len(args) {
+				return nil, fmt.Errorf("curl: option %s requires an argument", name)
+			}
+			value = args[i]
+		}
+		switch name {
+		case "-X", "--request":
+			method = strings.ToUpper(value)
+		case "--url":
+			rawURL = value
+		case "-H", "--header":
+			k, v, ok := strings.Cut(value, ":")
+			if !ok {
+				if k, ok = strings.CutSuffix(value, ";"); ok {
+					header.Add(k, "")
+				}
+				continue
+			}
+			if v = strings.TrimSpace(v); v != "" {
+				header.Add(strings.TrimSpace(k), v)
+			}
+		case "-A", "--user-agent":
+			header.Set("User-Agent", value)
+		case "-e", "--referer":
+			header.Set("Referer", value)
+		case "-b", "--cookie":
+			if !strings.Contains(value, "=") {
+				return nil, fmt.Errorf("curl: unsupported cookie file %q", value)
+			}
+			if c := header.Get("Cookie"); c != "" {
+				value = c + "; " + value
+			}
+			header.Set("Cookie", value)
+		case "-u", "--user":
+			user = &value
+		case "-d", "--data", "--data-ascii", "--data-binary":
+			if file, ok := strings.CutPrefix(value, "@"); ok {
+				b, err := os.ReadFile(file)
+				if err != nil {
+					return nil, err
+				}
+				value = string(b)
+				if name != "--data-binary" {
+					value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+				}
+			}
+			data = append(data, value)
+		case "--data-raw":
+			data = append(data, value)
+		case "--data-urlencode":
+			v, err := urlencodeData(value)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, v)
+		case "-F", "--form":
+			k, v, ok := strings.Cut(value, "=")
+			if !ok {
+				return nil, fmt.Errorf("curl: invalid form %q", value)
+			}
+			if file, ok := strings.CutPrefix(v, "@"); ok {
+				file, _, _ = strings.Cut(file, ";")
+				f, err := os.Open(file)
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, &File{ReadCloser: f, Fieldname: k, Filename: filepath.Base(file)})
+			} else {
+				params[k] = v
+			}
+		case "-m", "--max-time":
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("curl: invalid max time %q", value)
+			}
+			timeout = time.Duration(seconds * float64(time.Second))
+		case "-I", "--head":
+			head = true
+		case "-G", "--get":
+			get = true
+		}
+	}
+	if rawURL == "" {
+		return nil, errors.New("curl: no URL specified")
+	}
+
+	r := s.R().URL(rawURL).Timeout(timeout)
+	switch {
+	case head:
+		r.Method("HEAD")
+	case len(params) > 0 || len(files) > 0 || len(data) > 0 && !get:
+		r.Method("POST")
+	}
+	if method != "" {
+		r.Method(method)
+	}
+	for k, v := range header {
+		r.header[k] = v
+	}
+	if user != nil {
+		username, password, _ := strings.Cut(*user, ":")
+		r.BasicAuth(username, password)
+	}
+	switch {
+	case len(params) > 0 || len(files) > 0:
+		body, contentType, err := buildMultipart(params, files...)
+		if err != nil {
+			return nil, err
+		}
+		r.Header("Content-Type", contentType).Body(body)
+	case len(data) > 0 && get:
+		query, err := url.ParseQuery(strings.Join(data, "&"))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range query {
+			r.Query(k, v...)
+		}
+	case len(data) > 0:
+		if r.header.Get("Content-Type") == "" {
+			r.Header("Content-Type", "application/x-www-form-urlencoded")
+		}
+		r.Body(strings.NewReader(strings.Join(data, "&")))
+	}
+	return r, nil
+}
+
+// curlOptions lists the supported curl options and whether they take a value.
+// Options only affecting the output or the connection are accepted and ignored.
+var curlOptions = func() map[string]bool {
+	options := make(map[string]bool)
+	for _, name := range strings.Fields(`-X --request --url -H --header -A --user-agent -e --referer
+		-b --cookie -u --user -d --data --data-ascii --data-binary --data-raw --data-urlencode
+		-F --form -m --max-time -o --output -w --write-out --connect-timeout --retry`) {
+		options[name] = true
+	}
+	for _, name := range strings.Fields(`-I --head -G --get --compressed -L --location -s --silent
+		-S --show-error -k --insecure -v --verbose -i --include -g --globoff -f --fail
+		-N --no-buffer --http1.1 --http2`) {
+		options[name] = false
+	}
+	return options
+}()
+
+// urlencodeData encodes a --data-urlencode value of the form content,
+// =content, name=content, @file or name@file.
+func urlencodeData(value string) (string, error) {
+	if i := strings.IndexAny(value, "=@"); i >= 0 {
+		name, content := value[:i], value[i+1:]
+		if value[i] == '@' {
+			b, err := os.ReadFile(content)
+			if err != nil {
+				return "", err
+			}
+			content = string(b)
+		}
+		if name == "" {
+			return url.QueryEscape(content), nil
+		}
+		return name + "=" + url.QueryEscape(content), nil
+	}
+	return url.QueryEscape(value), nil
+}
+
+// splitShell splits cmd into words like a POSIX shell, supporting single,
+// double and ANSI-C ($'...') quoting and backslash line continuations.
+func splitShell(cmd string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(cmd); i++ {
+		c := cmd[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\\':
+			if i++; i == len(cmd) {
+				continue
+			}
+			if cmd[i] == '\r' && i+1 < len(cmd) && cmd[i+1] == '\n' {
+				i++
+			}
+			if cmd[i] == '\n' {
+				// Line continuation.
+				continue
+			}
+			word.WriteByte(cmd[i])
+			inWord = true
+		case c == '\'':
+			j := strings.IndexByte(cmd[i+1:], '\'')
+			if j < 0 {
+				return nil, errors.New("curl: unterminated quote")
+			}
+			word.WriteString(cmd[i+1 : i+1+j])
+			i += j + 1
+			inWord = true
+		case c == '$' && i+1 < len(cmd) && cmd[i+1] == '\'':
+			n, err := ansiCUnquote(cmd[i+2:], &word)
+			if err != nil {
+				return nil, err
+			}
+			i += n + 1
+			inWord = true
+		case c == '"':
+			for i++; ; i++ {
+				if i == len(cmd) {
+					return nil, errors.New("curl: unterminated quote")
+				}
+				if cmd[i] == '"' {
+					break
+				}
+				if cmd[i] == '\\' && i+1 < len(cmd) && strings.IndexByte("$`\"\\\n", cmd[i+1]) >= 0 {
+					if i++; cmd[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(cmd[i])
+			}
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// ansiCUnquote writes the unescaped contents of an ANSI-C quoted string
+// starting after $' to b and returns the number of bytes consumed,
+// including the closing quote.
+func ansiCUnquote(s string, b *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\'' {
+			return i + 1, nil
+		}
+		if c != '\\' || i+1 == len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch c = s[i]; c {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'e', 'E':
+			b.WriteByte(0x1b)
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case 'x', 'u', 'U':
+			digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+			j := i + 1
+			for j < len(s) && j < i+1+digits && isHex(s[j]) {
+				j++
+			}
+			if j == i+1 {
+				b.WriteByte('\\')
+				b.WriteByte(c)
+				continue
+			}
+			n, _ := strconv.ParseUint(s[i+1:j], 16, 32)
+			if c == 'x' {
+				b.WriteByte(byte(n))
+			} else {
+				b.WriteRune(rune(n))
+			}
+			i = j - 1
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			j := i
+			for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+				j++
+			}
+			n, _ := strconv.ParseUint(s[i:j], 8, 8)
+			b.WriteByte(byte(n))
+			i = j - 1
+		default:
+			// \\, \', \" and \? are unescaped; others are kept as is.
+			if !strings.ContainsRune(`\'"?`, rune(c)) {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(c)
+		}
+	}
+	return 0, errors.New("curl: unterminated quote")
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,153 @@
+package gohttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCurl(t *testing.T) {
+	s := NewSession()
+	s.Header.Set("X-Session", "1")
+	u, _ := url.Parse("https://example.com/")
+	s.SetCookie(u, "name", "value")
+
+	req, _ := http.NewRequest("GET", "https://example.com/path?q=a b", nil)
+	cmd, err := s.Curl(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"curl 'https://example.com/path?q=a b' ",
+		"-H 'Cookie: name=value'",
+		"-H 'X-Session: 1'",
+		"-H 'User-Agent: " + defaultAgent + "'",
+		"--compressed",
+	} {
+		if !strings.Contains(cmd, expect) {
+			t.Errorf("expected %q contains %q", cmd, expect)
+		}
+	}
+	if strings.Contains(cmd, "-X") || strings.Contains(cmd, "Accept-Encoding") {
+		t.Errorf("unexpected command: %q", cmd)
+	}
+
+	cmd, err = s.R().Method("PUT").URL("https://example.com/").Header("Accept-Encoding", "identity").Body(strings.NewReader("it's\n")).Curl()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []string{
+		"curl -X PUT https://example.com/ ",
+		"-H 'Accept-Encoding: identity'",
+		`--data-raw $'it\'s\n'`,
+	} {
+		if !strings.Contains(cmd, expect) {
+			t.Errorf("expected %q contains %q", cmd, expect)
+		}
+	}
+}
+
+func TestParseCurl(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		user, pass, _ := r.BasicAuth()
+		w.Write([]byte(strings.Join([]string{
+			r.Method,
+			r.URL.RequestURI(),
+			r.Header.Get("Content-Type"),
+			r.Header.Get("Cookie"),
+			r.Header.Get("X-Test"),
+			user + ":" + pass,
+			string(b),
+		}, "|")))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "data.txt")
+	if err := os.WriteFile(file, []byte("a=1\nb=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		cmd    string
+		expect string
+	}{
+		{
+			"curl '" + ts.URL + "/path' \\\n  -H 'X-Test: a b' \\\n  -b 'c=1; d=2' \\\n  --compressed",
+			"GET|/path||c=1; d=2|a b|:|",
+		},
+		{
+			`curl "` + ts.URL + `/login" -H "Content-Type: application/json" --data-raw $'{"name":"it\'s"}' -sSL`,
+			`POST|/login|application/json|||:|{"name":"it's"}`,
+		},
+		{
+			"curl -XPATCH " + ts.URL + " -d @" + file + " --data-urlencode 'c=x y' -u user:pass",
+			"PATCH|/|application/x-www-form-urlencoded|||user:pass|a=1b=2&c=x+y",
+		},
+		{
+			"curl -G " + ts.URL + "/search -d q=go -d page=2",
+			"GET|/search?page=2&q=go||||:|",
+		},
+	} {
+		r, err := ParseCurl(tc.cmd)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		resp, err := r.Do(context.Background())
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if s := resp.String(); s != tc.expect {
+			t.Errorf("#%d: expected %q; got %q", i, tc.expect, s)
+		}
+	}
+
+	r, err := ParseCurl("curl " + ts.URL + " -F name=value -F file=@" + file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := r.Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := resp.String(); !strings.HasPrefix(s, "POST|/|multipart/form-data; boundary=") || !strings.Contains(s, `filename="data.txt"`) {
+		t.Errorf("unexpected multipart request: %q", s)
+	}
+
+	for _, cmd := range []string{
+		"wget " + ts.URL,
+		"curl",
+		"curl " + ts.URL + " --unknown",
+		"curl " + ts.URL + " -H",
+		"curl '" + ts.URL,
+	} {
+		if _, err := ParseCurl(cmd); err == nil {
+			t.Errorf("%q: gave nil error; want error", cmd)
+		}
+	}
+
+	// Round trip.
+	s := NewSession()
+	cmd, err := s.R().Method("POST").URL(ts.URL+"/rt").Header("X-Test", "it's").Form(url.Values{"a": {"1"}}).Curl()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err = s.ParseCurl(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = r.Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, expect := resp.String(), "POST|/rt|application/x-www-form-urlencoded||it's|:|a=1"; s != expect {
+		t.Errorf("expected %q; got %q", expect, s)
+	}
+}
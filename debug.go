@@ -2,10 +2,13 @@ package gohttp
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httputil"
 	"regexp"
+	"time"
 )
 
 type debugger struct {
@@ -13,6 +16,7 @@ type debugger struct {
 	w        io.Writer
 	reqBody  bool
 	respBody bool
+	timings  bool
 }
 
 func (t *debugger) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -24,6 +28,12 @@ func (t *debugger) RoundTrip(req *http.Request) (*http.Response, error) {
 		return nil, err
 	}
 	t.Write("-> ", reqBody)
+	var trace *connTrace
+	if t.timings {
+		var ctx context.Context
+		trace, ctx = newConnTrace(req.Context())
+		req = req.WithContext(ctx)
+	}
 	res, err := t.rt.RoundTrip(req)
 	if err != nil {
 		return nil, err
@@ -33,9 +43,22 @@ func (t *debugger) RoundTrip(req *http.Request) (*http.Response, error) {
 		return nil, err
 	}
 	t.Write("<- ", respBody)
+	if trace != nil {
+		timings := trace.stats(time.Time{})
+		t.Write("-- ", fmt.Appendf(nil,
+			"dns=%s connect=%s tls=%s ttfb=%s total=%s reused=%t remote=%s",
+			timings.DNS, timings.Connect, timings.TLSHandshake, timings.TimeToFirstByte,
+			timings.Total, timings.ConnReused, timings.RemoteAddr,
+		))
+	}
 	return res, nil
 }
 
+// Unwrap returns the underlying RoundTripper.
+func (t *debugger) Unwrap() http.RoundTripper {
+	return t.rt
+}
+
 var lineStart = regexp.MustCompile(`(?m)^`)
 
 func (w *debugger) Write(prefix string, buf []byte) {
@@ -0,0 +1,408 @@
+package gohttp
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrChecksum is returned when a downloaded file does not match the expected checksum.
+var ErrChecksum = errors.New("checksum mismatch")
+
+// DownloadOptions configures a download.
+type DownloadOptions struct {
+	// Headers are additional request headers.
+	Headers H
+	// Resume continues a previous partial download of the same file
+	// using Range and If-Range requests, and keeps the partial file on failure.
+	Resume bool
+	// Progress is called as the download proceeds.
+	Progress func(Progress)
+	// SHA256 is the expected hex encoded SHA-256 checksum of the file.
+	SHA256 string
+	// MD5 is the expected hex encoded MD5 checksum of the file.
+	MD5 string
+	// Segments is the number of byte ranges fetched concurrently. If greater
+	// than 1 and the server accepts byte ranges, the file is downloaded in
+	// segments and Resume is ignored.
+	Segments int
+	// SegmentRetries is the number of times a failed segment is retried
+	// from where it stopped.
+	SegmentRetries int
+}
+
+// Progress reports the progress of a download.
+type Progress struct {
+	// Downloaded is the number of bytes of the file downloaded so far,
+	// including bytes of a resumed partial download.
+	Downloaded int64
+	// Total is the total size of the file, or -1 if unknown.
+	Total int64
+	// Rate is the average download rate in bytes per second.
+	Rate float64
+}
+
+// Download downloads the URL to file using default session.
+func Download(url, file string, opt *DownloadOptions) (int64, error) {
+	return defaultSession.Download(url, file, opt)
+}
+
+// DownloadWithContext downloads the URL to file with context using default session.
+func DownloadWithContext(ctx context.Context, url, file string, opt *DownloadOptions) (int64, error) {
+	return defaultSession.DownloadWithContext(ctx, url, file, opt)
+}
+
+// Download downloads the URL to file. It returns the size of file.
+func (s *Session) Download(url, file string, opt *DownloadOptions) (int64, error) {
+	return s.DownloadWithContext(context.Background(), url, file, opt)
+}
+
+// DownloadWithContext downloads the URL to file with context. The body is
+// streamed to file+".part" which is renamed to file once complete and
+// verified. It returns the size of file.
+func (s *Session) DownloadWithContext(ctx context.Context, url, file string, opt *DownloadOptions) (n int64, err error) {
+	if opt == nil {
+		opt = new(DownloadOptions)
+	}
+	if opt.Segments > 1 {
+		if n, ok, err := s.downloadSegments(ctx, url, file, opt); ok {
+			return n, err
+		}
+	}
+	part, meta := file+".part", file+".part.meta"
+	defer func() {
+		if err != nil && (!opt.Resume || errors.Is(err, ErrChecksum)) {
+			os.Remove(part)
+			os.Remove(meta)
+		}
+	}()
+
+	var offset int64
+	var validator string
+	if opt.Resume {
+		if info, err := os.Stat(part); err == nil {
+			offset = info.Size()
+			if b, err := os.ReadFile(meta); err == nil {
+				validator = string(b)
+			}
+		}
+	}
+
+	// Ranges and sizes refer to the content as sent, so no content coding is accepted.
+	r := s.R().URL(url).Header("Accept-Encoding", "identity").Headers(opt.Headers).Stream(true).
+		Expect(http.StatusOK, http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable)
+	if offset > 0 {
+		r.Header("Range", fmt.Sprintf("bytes=%d-", offset))
+		if validator != "" {
+			r.Header("If-Range", validator)
+		}
+	}
+	resp, err := r.Do(ctx)
+	if err != nil {
+		if resp != nil {
+			resp.Close()
+		}
+		return 0, err
+	}
+	defer resp.Close()
+
+	total := resp.ContentLength
+	flag := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusOK:
+		offset = 0
+		flag |= os.O_TRUNC
+	case http.StatusPartialContent:
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return 0, fmt.Errorf("unexpected Content-Range: %q", resp.Header.Get("Content-Range"))
+		}
+		total = size
+		flag |= os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		if _, size, ok := parseContentRange(resp.Header.Get("Content-Range")); offset == 0 || !ok || size != offset {
+			return 0, resp.statusError()
+		}
+		total = offset
+		flag |= os.O_APPEND
+	}
+	if v := resp.Header.Get("ETag"); v != "" && !strings.HasPrefix(v, "W/") {
+		validator = v
+	} else {
+		validator = resp.Header.Get("Last-Modified")
+	}
+	if opt.Resume && validator != "" {
+		if err := os.WriteFile(meta, []byte(validator), 0644); err != nil {
+			return 0, err
+		}
+	}
+
+	f, err := os.OpenFile(part, flag, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	p := newProgress(offset, total, opt.Progress)
+	if resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		if _, err := io.Copy(&progressWriter{f, p}, resp); err != nil {
+			return 0, err
+		}
+	}
+	p.report()
+	if total >= 0 && p.downloaded != total {
+		return 0, fmt.Errorf("download incomplete: %d of %d bytes", p.downloaded, total)
+	}
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+
+	if err := verifyChecksum(part, opt); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(part, file); err != nil {
+		return 0, err
+	}
+	os.Remove(meta)
+	return p.downloaded, nil
+}
+
+// downloadSegments downloads the URL to file in opt.Segments byte ranges
+// fetched concurrently. It reports false if the server does not accept
+// byte ranges or the size of file is unknown.
+func (s *Session) downloadSegments(ctx context.Context, url, file string, opt *DownloadOptions) (n int64, ok bool, err error) {
+	headers := H{"Accept-Encoding": "identity"}
+	maps.Copy(headers, opt.Headers)
+	head, err := s.R().Method(http.MethodHead).URL(url).Headers(headers).Expect(http.StatusOK).Do(ctx)
+	if head == nil {
+		return 0, true, err
+	}
+	head.Close()
+	size := head.ContentLength
+	// Any other status than 200 OK falls back to a single download.
+	if err != nil || head.Header.Get("Accept-Ranges") != "bytes" || size <= 0 {
+		return 0, false, nil
+	}
+	validator := head.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = head.Header.Get("Last-Modified")
+	}
+
+	part := file + ".part"
+	f, err := os.Create(part)
+	if err != nil {
+		return 0, true, err
+	}
+	defer func() {
+		f.Close()
+		if err != nil {
+			os.Remove(part)
+		}
+	}()
+	if err = f.Truncate(size); err != nil {
+		return 0, true, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	p := newProgress(0, size, opt.Progress)
+	segment := (size + int64(opt.Segments) - 1) / int64(opt.Segments)
+	var wg sync.WaitGroup
+	var once sync.Once
+	for start := int64(0); start < size; start += segment {
+		end := min(start+segment, size) - 1
+		wg.Go(func() {
+			if e := s.downloadSegment(ctx, url, headers, validator, f, start, end, opt.SegmentRetries, p); e != nil {
+				once.Do(func() {
+					err = fmt.Errorf("download segment %d-%d: %w", start, end, e)
+					cancel()
+				})
+			}
+		})
+	}
+	wg.Wait()
+	if err != nil {
+		return 0, true, err
+	}
+	p.report()
+
+	if err = f.Sync(); err != nil {
+		return 0, true, err
+	}
+	if err = f.Close(); err != nil {
+		return 0, true, err
+	}
+	if err = verifyChecksum(part, opt); err != nil {
+		return 0, true, err
+	}
+	if err = os.Rename(part, file); err != nil {
+		return 0, true, err
+	}
+	return size, true, nil
+}
+
+// downloadSegment writes bytes from start to end of the URL to f at the same
+// offset, retrying from where it stopped at most retries times.
+func (s *Session) downloadSegment(ctx context.Context, url string, headers H, validator string, f *os.File, start, end int64, retries int, p *progress) (err error) {
+	for attempt := 0; attempt <= retries; attempt++ {
+		var n int64
+		n, err = s.fetchRange(ctx, url, headers, validator, f, start, end, p)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		start += n
+	}
+	return
+}
+
+func (s *Session) fetchRange(ctx context.Context, url string, headers H, validator string, f *os.File, start, end int64, p *progress) (int64, error) {
+	r := s.R().URL(url).Headers(headers).Header("Range", fmt.Sprintf("bytes=%d-%d", start, end)).Stream(true).Expect(http.StatusPartialContent)
+	if validator != "" {
+		r.Header("If-Range", validator)
+	}
+	resp, err := r.Do(ctx)
+	if err != nil {
+		if resp != nil {
+			resp.Close()
+		}
+		return 0, err
+	}
+	defer resp.Close()
+
+	if s, _, ok := parseContentRange(resp.Header.Get("Content-Range")); !ok || s != start {
+		return 0, fmt.Errorf("unexpected Content-Range: %q", resp.Header.Get("Content-Range"))
+	}
+	n, err := io.Copy(&progressWriter{io.NewOffsetWriter(f, start), p}, io.LimitReader(resp, end-start+1))
+	if err == nil && n != end-start+1 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// progress tracks the progress of a download which may be written concurrently.
+type progress struct {
+	mu         sync.Mutex
+	downloaded int64
+	total      int64
+	written    int64
+	start      time.Time
+	fn         func(Progress)
+}
+
+func newProgress(downloaded, total int64, fn func(Progress)) *progress {
+	return &progress{downloaded: downloaded, total: total, start: time.Now(), fn: fn}
+}
+
+func (p *progress) add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.downloaded += n
+	p.written += n
+	p.reportLocked()
+}
+
+func (p *progress) report() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reportLocked()
+}
+
+func (p *progress) reportLocked() {
+	if p.fn == nil {
+		return
+	}
+	var rate float64
+	if elapsed := time.Since(p.start).Seconds(); elapsed > 0 {
+		rate = float64(p.written) / elapsed
+	}
+	p.fn(Progress{Downloaded: p.downloaded, Total: p.total, Rate: rate})
+}
+
+type progressWriter struct {
+	w io.Writer
+	p *progress
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.p.add(int64(n))
+	return n, err
+}
+
+// parseContentRange parses a Content-Range header value of the form
+// "bytes start-end/size" or "bytes */size".
+func parseContentRange(s string) (start, size int64, ok bool) {
+	s, ok = strings.CutPrefix(s, "bytes ")
+	if !ok {
+		return
+	}
+	r, sizeStr, ok := strings.Cut(s, "/")
+	if !ok {
+		return
+	}
+	if sizeStr == "*" {
+		size = -1
+	} else if size, ok = parseInt(sizeStr); !ok {
+		return
+	}
+	if r == "*" {
+		return 0, size, true
+	}
+	startStr, _, ok := strings.Cut(r, "-")
+	if !ok {
+		return
+	}
+	start, ok = parseInt(startStr)
+	return
+}
+
+func parseInt(s string) (int64, bool) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil && n >= 0
+}
+
+func verifyChecksum(file string, opt *DownloadOptions) error {
+	for _, c := range []struct {
+		expected string
+		hash     func() hash.Hash
+	}{
+		{opt.SHA256, sha256.New},
+		{opt.MD5, md5.New},
+	} {
+		if c.expected == "" {
+			continue
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		h := c.hash()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		if sum := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(sum, c.expected) {
+			return fmt.Errorf("%w: expected %s; got %s", ErrChecksum, c.expected, sum)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package gohttp
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	var ranges []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/file" {
+			http.NotFound(w, r)
+			return
+		}
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer ts.Close()
+
+	sha := sha256.Sum256(content)
+	sum := md5.Sum(content)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+
+	var last Progress
+	n, err := NewSession().Download(ts.URL+"/file", file, &DownloadOptions{
+		Progress: func(p Progress) { last = p },
+		SHA256:   hex.EncodeToString(sha[:]),
+		MD5:      strings.ToUpper(hex.EncodeToString(sum[:])),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(content)) {
+		t.Errorf("expected %d bytes; got %d", len(content), n)
+	}
+	if b, _ := os.ReadFile(file); !bytes.Equal(b, content) {
+		t.Error("downloaded content mismatch")
+	}
+	if last.Downloaded != int64(len(content)) || last.Total != int64(len(content)) {
+		t.Errorf("unexpected last progress: %+v", last)
+	}
+	if _, err := os.Stat(file + ".part"); !os.IsNotExist(err) {
+		t.Error("expected partial file removed")
+	}
+
+	// resume with matching validator
+	os.WriteFile(file+".part", content[:1000], 0644)
+	os.WriteFile(file+".part.meta", []byte(`"v1"`), 0644)
+	ranges = nil
+	var first *Progress
+	if _, err := NewSession().Download(ts.URL+"/file", file, &DownloadOptions{
+		Resume: true,
+		Progress: func(p Progress) {
+			if first == nil {
+				first = &p
+			}
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=1000-" {
+		t.Errorf("expected range request %q; got %q", "bytes=1000-", ranges)
+	}
+	if b, _ := os.ReadFile(file); !bytes.Equal(b, content) {
+		t.Error("resumed content mismatch")
+	}
+	if first == nil || first.Downloaded <= 1000 {
+		t.Errorf("expected progress includes resumed bytes; got %+v", first)
+	}
+	if _, err := os.Stat(file + ".part.meta"); !os.IsNotExist(err) {
+		t.Error("expected meta file removed")
+	}
+
+	// resume with changed validator restarts from scratch
+	os.WriteFile(file+".part", []byte("garbage"), 0644)
+	os.WriteFile(file+".part.meta", []byte(`"v0"`), 0644)
+	if _, err := NewSession().Download(ts.URL+"/file", file, &DownloadOptions{Resume: true}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(file); !bytes.Equal(b, content) {
+		t.Error("restarted content mismatch")
+	}
+
+	// already complete partial file, also with raise for status
+	raise := NewSession()
+	raise.SetRaiseForStatus(true)
+	os.WriteFile(file+".part", content, 0644)
+	os.WriteFile(file+".part.meta", []byte(`"v1"`), 0644)
+	if n, err := raise.Download(ts.URL+"/file", file, &DownloadOptions{Resume: true}); err != nil {
+		t.Fatal(err)
+	} else if n != int64(len(content)) {
+		t.Errorf("expected %d bytes; got %d", len(content), n)
+	}
+	os.WriteFile(file+".part", content, 0644)
+	os.WriteFile(file+".part.meta", []byte(`"v1"`), 0644)
+	if n, err := NewSession().Download(ts.URL+"/file", file, &DownloadOptions{Resume: true}); err != nil {
+		t.Fatal(err)
+	} else if n != int64(len(content)) {
+		t.Errorf("expected %d bytes; got %d", len(content), n)
+	}
+
+	other := filepath.Join(dir, "other")
+	if _, err := NewSession().Download(ts.URL+"/file", other, &DownloadOptions{SHA256: "00"}); !errors.Is(err, ErrChecksum) {
+		t.Errorf("expected ErrChecksum; got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected no file left after checksum mismatch; got %d files", len(entries))
+	}
+	if _, err := NewSession().Download(ts.URL+"/notfound", other, nil); err == nil {
+		t.Error("gave nil error; want error")
+	}
+	var se *StatusError
+	if _, err := raise.Download(ts.URL+"/notfound", other, nil); !errors.As(err, &se) || se.StatusCode != http.StatusNotFound {
+		t.Errorf("expected *StatusError; got %v", err)
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	for _, tc := range []struct {
+		s           string
+		start, size int64
+		ok          bool
+	}{
+		{"bytes 0-99/100", 0, 100, true},
+		{"bytes 10-99/*", 10, -1, true},
+		{"bytes */100", 0, 100, true},
+		{"bytes 10-99", 0, 0, false},
+		{"items 0-1/2", 0, 0, false},
+		{"bytes x-1/2", 0, 0, false},
+	} {
+		if start, size, ok := parseContentRange(tc.s); ok != tc.ok || ok && (start != tc.start || size != tc.size) {
+			t.Errorf("%q: expected %d, %d, %t; got %d, %d, %t", tc.s, tc.start, tc.size, tc.ok, start, size, ok)
+		}
+	}
+}
+
+func TestDownloadSegments(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	var mu sync.Mutex
+	var requests, norange []string
+	failed := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.Header.Get("Range"))
+		fail := !failed && r.Header.Get("Range") != "" && !strings.HasPrefix(r.Header.Get("Range"), "bytes=0-")
+		if fail {
+			failed = true
+		}
+		mu.Unlock()
+
+		if r.URL.Path == "/nohead" && r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Path == "/norange" || r.URL.Path == "/nohead" {
+			mu.Lock()
+			norange = append(norange, r.Method)
+			mu.Unlock()
+			w.Write(content)
+			return
+		}
+		if fail {
+			w.Header().Set("Content-Length", "100")
+			w.Header().Set("Content-Range", "bytes "+strings.TrimPrefix(r.Header.Get("Range"), "bytes=")+"/100000")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte("0123456789"))
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer ts.Close()
+
+	file := filepath.Join(t.TempDir(), "file")
+	sha := sha256.Sum256(content)
+	var last Progress
+	n, err := NewSession().Download(ts.URL+"/file", file, &DownloadOptions{
+		Segments:       4,
+		SegmentRetries: 1,
+		SHA256:         hex.EncodeToString(sha[:]),
+		Progress:       func(p Progress) { last = p },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(content)) {
+		t.Errorf("expected %d bytes; got %d", len(content), n)
+	}
+	if b, _ := os.ReadFile(file); !bytes.Equal(b, content) {
+		t.Error("downloaded content mismatch")
+	}
+	if last.Downloaded != int64(len(content)) || last.Total != int64(len(content)) {
+		t.Errorf("unexpected last progress: %+v", last)
+	}
+	if len(requests) != 6 || requests[0] != "HEAD " {
+		t.Errorf("expected HEAD, 4 segments and 1 retry; got %q", requests)
+	}
+
+	mu.Lock()
+	requests, failed = nil, false
+	mu.Unlock()
+	if _, err := NewSession().Download(ts.URL+"/file", file, &DownloadOptions{Segments: 4}); err == nil {
+		t.Error("gave nil error; want error")
+	}
+	if _, err := os.Stat(file + ".part"); !os.IsNotExist(err) {
+		t.Error("expected partial file removed")
+	}
+
+	if _, err := NewSession().Download(ts.URL+"/norange", file, &DownloadOptions{Segments: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if len(norange) != 2 || norange[0] != "HEAD" || norange[1] != "GET" {
+		t.Errorf("expected fallback to single download; got %q", norange)
+	}
+	if b, _ := os.ReadFile(file); !bytes.Equal(b, content) {
+		t.Error("downloaded content mismatch")
+	}
+
+	raise := NewSession()
+	raise.SetRaiseForStatus(true)
+	os.Remove(file)
+	if _, err := raise.Download(ts.URL+"/nohead", file, &DownloadOptions{Segments: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(file); !bytes.Equal(b, content) {
+		t.Error("downloaded content mismatch")
+	}
+}
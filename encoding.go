@@ -0,0 +1,161 @@
+package gohttp
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Decoder returns a reader decoding a content coding from r.
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+// builtinCodings lists the content codings decoded by default in order of preference.
+var builtinCodings = []string{"gzip", "deflate", "br", "zstd"}
+
+var defaultDecoders = map[string]Decoder{
+	"gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"deflate": newDeflateReader,
+	"br": func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(brotli.NewReader(r)), nil
+	},
+	"zstd": func(r io.Reader) (io.ReadCloser, error) {
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	},
+}
+
+// newDeflateReader returns a reader decoding deflate content coding, which is
+// zlib-wrapped (RFC 1950) by the standard but sent as raw deflate (RFC 1951)
+// by some servers. The format is detected by the zlib header.
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	if header, err := br.Peek(2); err == nil &&
+		header[0]&0x0f == 8 && header[0]>>4 <= 7 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// DecodeError records an error decoding a content coding of the response body.
+type DecodeError struct {
+	Coding string
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return "decode " + e.Coding + " body: " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// decodeReader wraps errors from a decoder other than io.EOF in DecodeError.
+type decodeReader struct {
+	io.ReadCloser
+	coding string
+}
+
+func (r *decodeReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		switch err.(type) {
+		case *DecodeError, *ResponseTooLargeError:
+		default:
+			err = &DecodeError{r.coding, err}
+		}
+	}
+	return n, err
+}
+
+// RegisterDecoder registers the decoder of a content coding for default session.
+func RegisterDecoder(coding string, d Decoder) {
+	defaultSession.RegisterDecoder(coding, d)
+}
+
+// RegisterDecoder registers the decoder of a content coding for Session,
+// replacing any existing one. Registered codings are advertised in the
+// Accept-Encoding header. Nil d removes the coding.
+func (s *Session) RegisterDecoder(coding string, d Decoder) {
+	coding = strings.ToLower(coding)
+	decoders := maps.Clone(s.decoders)
+	if decoders == nil {
+		decoders = maps.Clone(defaultDecoders)
+	}
+	if d == nil {
+		delete(decoders, coding)
+	} else {
+		decoders[coding] = d
+	}
+	s.decoders = decoders
+}
+
+// acceptEncoding returns the Accept-Encoding header value listing decoders.
+func acceptEncoding(decoders map[string]Decoder) string {
+	var codings []string
+	for _, coding := range builtinCodings {
+		if _, ok := decoders[coding]; ok {
+			codings = append(codings, coding)
+		}
+	}
+	for _, coding := range slices.Sorted(maps.Keys(decoders)) {
+		if !slices.Contains(builtinCodings, coding) {
+			codings = append(codings, coding)
+		}
+	}
+	if len(codings) == 0 {
+		return "identity"
+	}
+	return strings.Join(codings, ", ")
+}
+
+// contentCodings returns the content codings listed in the
+// Content-Encoding header in the order they were applied.
+func contentCodings(header http.Header) (codings []string) {
+	for _, v := range header.Values("Content-Encoding") {
+		for coding := range strings.SplitSeq(v, ",") {
+			if coding = strings.ToLower(strings.TrimSpace(coding)); coding != "" && coding != "identity" {
+				codings = append(codings, coding)
+			}
+		}
+	}
+	return
+}
+
+// decodeBody wraps body with decoders for the codings listed in header.
+// If any coding has no decoder, body is returned undecoded.
+// The returned closers should be closed along with body.
+func decodeBody(body io.Reader, header http.Header, decoders map[string]Decoder) (io.Reader, []io.Closer, error) {
+	codings := contentCodings(header)
+	for _, coding := range codings {
+		if _, ok := decoders[coding]; !ok {
+			return body, nil, nil
+		}
+	}
+	var closers []io.Closer
+	for _, coding := range slices.Backward(codings) {
+		r, err := decoders[coding](body)
+		if err != nil {
+			closeAll(closers)
+			if _, ok := err.(*DecodeError); !ok {
+				err = &DecodeError{coding, err}
+			}
+			return nil, nil, err
+		}
+		body = &decodeReader{r, coding}
+		closers = append(closers, r)
+	}
+	return body, closers, nil
+}
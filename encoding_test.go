@@ -0,0 +1,203 @@
+package gohttp
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func encode(t *testing.T, s string, codings ...string) []byte {
+	t.Helper()
+	b := []byte(s)
+	for _, coding := range codings {
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		switch coding {
+		case "gzip":
+			w = gzip.NewWriter(&buf)
+		case "deflate":
+			w = zlib.NewWriter(&buf)
+		case "br":
+			w = brotli.NewWriter(&buf)
+		case "zstd":
+			var err error
+			if w, err = zstd.NewWriter(&buf); err != nil {
+				t.Fatal(err)
+			}
+		case "rot13":
+			w = nopWriteCloser{&buf}
+			b = []byte(strings.Map(rot13, string(b)))
+		}
+		w.Write(b)
+		w.Close()
+		b = buf.Bytes()
+	}
+	return b
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func rot13(r rune) rune {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return 'a' + (r-'a'+13)%26
+	case r >= 'A' && r <= 'Z':
+		return 'A' + (r-'A'+13)%26
+	}
+	return r
+}
+
+func TestDecoders(t *testing.T) {
+	for _, tc := range []struct {
+		header  []string
+		codings []string
+	}{
+		{[]string{"br"}, []string{"br"}},
+		{[]string{"zstd"}, []string{"zstd"}},
+		{[]string{"gzip, br"}, []string{"gzip", "br"}},
+		{[]string{"zstd", "GZIP"}, []string{"zstd", "gzip"}},
+		{[]string{"identity"}, nil},
+	} {
+		r, err := buildResponse(&http.Response{
+			Header: http.Header{"Content-Encoding": tc.header},
+			Body:   io.NopCloser(bytes.NewReader(encode(t, "Hello, world!", tc.codings...))),
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := r.String(); s != "Hello, world!" {
+			t.Errorf("%q: expected %q; got %q", tc.header, "Hello, world!", s)
+		}
+		r.Close()
+	}
+
+	r, err := buildResponse(&http.Response{
+		Header: http.Header{"Content-Encoding": {"compress"}},
+		Body:   io.NopCloser(strings.NewReader("raw")),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := r.String(); s != "raw" {
+		t.Errorf("expected undecoded body %q; got %q", "raw", s)
+	}
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Encoding", "rot13, br")
+		w.Write(encode(t, "Hello, world!", "rot13", "br"))
+	}))
+	defer ts.Close()
+
+	resp, err := NewSession().Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ae := resp.Header.Get("X-Accept-Encoding"); ae != "gzip, deflate, br, zstd" {
+		t.Errorf("expected Accept-Encoding %q; got %q", "gzip, deflate, br, zstd", ae)
+	}
+	if s := resp.String(); s == "Hello, world!" {
+		t.Error("expected body not decoded with unknown coding")
+	}
+
+	s := NewSession()
+	s.RegisterDecoder("ROT13", func(r io.Reader) (io.ReadCloser, error) {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(strings.NewReader(strings.Map(rot13, string(b)))), nil
+	})
+	s.RegisterDecoder("deflate", nil)
+	resp, err = s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ae := resp.Header.Get("X-Accept-Encoding"); ae != "gzip, br, zstd, rot13" {
+		t.Errorf("expected Accept-Encoding %q; got %q", "gzip, br, zstd, rot13", ae)
+	}
+	if s := resp.String(); s != "Hello, world!" {
+		t.Errorf("expected %q; got %q", "Hello, world!", s)
+	}
+	if _, ok := defaultDecoders["rot13"]; ok {
+		t.Error("expected default decoders not changed")
+	}
+
+	inflight := s.options().decoders
+	s.RegisterDecoder("x-test", func(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil })
+	if _, ok := inflight["x-test"]; ok {
+		t.Error("expected decoders of in-flight requests not changed")
+	}
+	s.RegisterDecoder("x-test", nil)
+
+	for _, coding := range builtinCodings {
+		s.RegisterDecoder(coding, nil)
+	}
+	s.RegisterDecoder("rot13", nil)
+	if ae := acceptEncoding(s.decoders); ae != "identity" {
+		t.Errorf("expected Accept-Encoding %q; got %q", "identity", ae)
+	}
+}
+
+func TestDeflate(t *testing.T) {
+	var buf bytes.Buffer
+	fw, _ := flate.NewWriter(&buf, flate.BestCompression)
+	fw.Write([]byte("raw deflate"))
+	fw.Close()
+
+	for expect, body := range map[string][]byte{"zlib": encode(t, "zlib", "deflate"), "raw deflate": buf.Bytes()} {
+		r, err := buildResponse(&http.Response{
+			Header: http.Header{"Content-Encoding": {"deflate"}},
+			Body:   io.NopCloser(bytes.NewReader(body)),
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := r.String(); s != expect {
+			t.Errorf("expected %q; got %q", expect, s)
+		}
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	for _, coding := range []string{"gzip", "deflate", "br", "zstd"} {
+		body := encode(t, `{"hello":"world"}`, coding)
+		body = body[:len(body)-4]
+		r, err := buildResponse(&http.Response{
+			Header: http.Header{"Content-Encoding": {coding}},
+			Body:   io.NopCloser(bytes.NewReader(body)),
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data any
+		var de *DecodeError
+		if err := r.JSON(&data); !errors.As(err, &de) || de.Coding != coding {
+			t.Errorf("%s: expected *DecodeError; got %v", coding, err)
+		}
+		if s := r.String(); s != "" {
+			t.Errorf("%s: expected empty string for corrupted body; got %q", coding, s)
+		}
+	}
+
+	if _, err := buildResponse(&http.Response{
+		Header: http.Header{"Content-Encoding": {"gzip"}},
+		Body:   io.NopCloser(strings.NewReader("not gzip")),
+	}, nil); !errors.As(err, new(*DecodeError)) {
+		t.Errorf("expected *DecodeError; got %v", err)
+	}
+}
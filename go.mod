@@ -2,6 +2,10 @@ module github.com/sunshineplan/gohttp
 
 go 1.25.0
 
-require golang.org/x/net v0.56.0
+require (
+	github.com/andybalholm/brotli v1.2.6
+	github.com/klauspost/compress v1.20.1
+	golang.org/x/net v0.56.0
+)
 
 require golang.org/x/text v0.38.0 // indirect
@@ -4,12 +4,13 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"slices"
 	"time"
 )
 
 var (
 	defaultAgent   = "Go-HTTP-Client"
-	defaultSession = newSession(http.DefaultClient)
+	defaultSession = newSession(new(http.Client))
 )
 
 // H represents the key-value pairs in an HTTP header.
@@ -17,10 +18,9 @@ type H map[string]string
 
 func defaultHeaders() H {
 	return H{
-		"User-Agent":      defaultAgent,
-		"Accept-Encoding": "gzip, deflate",
-		"Accept":          "*/*",
-		"Connection":      "keep-alive",
+		"User-Agent": defaultAgent,
+		"Accept":     "*/*",
+		"Connection": "keep-alive",
 	}
 }
 
@@ -31,23 +31,41 @@ func SetAgent(agent string) {
 	}
 }
 
+// SetDebug sets default client to dump requests and responses to w,
+// optionally including bodies. Nil w disables debugging.
 func SetDebug(w io.Writer, reqBody, respBody bool) {
 	defaultSession.SetDebug(w, reqBody, respBody)
 }
 
+// SetDebugTimings sets whether default client debug output includes the
+// timing breakdown of each exchange.
+func SetDebugTimings(timings bool) {
+	defaultSession.SetDebugTimings(timings)
+}
+
 // SetProxy sets default client transport proxy.
 func SetProxy(proxy string) error {
 	return defaultSession.SetProxy(proxy)
 }
 
 // SetNoProxy sets default client use no proxy.
-func SetNoProxy() {
-	defaultSession.SetNoProxy()
+func SetNoProxy() error {
+	return defaultSession.SetNoProxy()
 }
 
 // SetProxyFromEnvironment sets default client use environment proxy.
-func SetProxyFromEnvironment() {
-	defaultSession.SetProxyFromEnvironment()
+func SetProxyFromEnvironment() error {
+	return defaultSession.SetProxyFromEnvironment()
+}
+
+// SetStream sets whether default client responses are streamed.
+func SetStream(stream bool) {
+	defaultSession.SetStream(stream)
+}
+
+// SetMaxResponseBytes sets default response body size limits.
+func SetMaxResponseBytes(compressed, decompressed int64) {
+	defaultSession.SetMaxResponseBytes(compressed, decompressed)
 }
 
 // SetTimeout sets default timeout. Zero means no timeout.
@@ -97,6 +115,52 @@ func PostWithContext(ctx context.Context, url string, headers H, data any) (*Res
 	return defaultSession.PostWithContext(ctx, url, headers, data)
 }
 
+// Put issues a PUT to the specified URL with headers.
+// Put data should be one of nil, io.Reader, url.Values, string map or struct.
+func Put(url string, headers H, data any) (*Response, error) {
+	return defaultSession.Put(url, headers, data)
+}
+
+// PutWithContext issues a PUT to the specified URL with context and headers.
+// Put data should be one of nil, io.Reader, url.Values, string map or struct.
+func PutWithContext(ctx context.Context, url string, headers H, data any) (*Response, error) {
+	return defaultSession.PutWithContext(ctx, url, headers, data)
+}
+
+// Patch issues a PATCH to the specified URL with headers.
+// Patch data should be one of nil, io.Reader, url.Values, string map or struct.
+func Patch(url string, headers H, data any) (*Response, error) {
+	return defaultSession.Patch(url, headers, data)
+}
+
+// PatchWithContext issues a PATCH to the specified URL with context and headers.
+// Patch data should be one of nil, io.Reader, url.Values, string map or struct.
+func PatchWithContext(ctx context.Context, url string, headers H, data any) (*Response, error) {
+	return defaultSession.PatchWithContext(ctx, url, headers, data)
+}
+
+// Delete issues a DELETE to the specified URL with headers.
+// Delete data should be one of nil, io.Reader, url.Values, string map or struct.
+func Delete(url string, headers H, data any) (*Response, error) {
+	return defaultSession.Delete(url, headers, data)
+}
+
+// DeleteWithContext issues a DELETE to the specified URL with context and headers.
+// Delete data should be one of nil, io.Reader, url.Values, string map or struct.
+func DeleteWithContext(ctx context.Context, url string, headers H, data any) (*Response, error) {
+	return defaultSession.DeleteWithContext(ctx, url, headers, data)
+}
+
+// Options issues an OPTIONS to the specified URL with headers.
+func Options(url string, headers H) (*Response, error) {
+	return defaultSession.Options(url, headers)
+}
+
+// OptionsWithContext issues an OPTIONS to the specified URL with context and headers.
+func OptionsWithContext(ctx context.Context, url string, headers H) (*Response, error) {
+	return defaultSession.OptionsWithContext(ctx, url, headers)
+}
+
 // Upload issues a POST to the specified URL with a multipart document.
 func Upload(url string, headers H, params map[string]string, files ...*File) (*Response, error) {
 	return defaultSession.Upload(url, headers, params, files...)
@@ -107,13 +171,82 @@ func UploadWithContext(ctx context.Context, url string, headers H, params map[st
 	return defaultSession.UploadWithContext(ctx, url, headers, params, files...)
 }
 
+// requestOptions holds the Session settings which can be overridden per request.
+type requestOptions struct {
+	retry              *RetryPolicy
+	stream             bool
+	decoders           map[string]Decoder
+	maxBytes           int64
+	maxCompressedBytes int64
+	raiseForStatus     bool
+	errorTypes         map[string]func() error
+	codecs             map[string]Codec
+	expect             []int
+}
+
+func (s *Session) options() requestOptions {
+	decoders := s.decoders
+	if decoders == nil {
+		decoders = defaultDecoders
+	}
+	return requestOptions{
+		retry:              s.retry,
+		stream:             s.stream,
+		decoders:           decoders,
+		maxBytes:           s.maxBytes,
+		maxCompressedBytes: s.maxCompressedBytes,
+		raiseForStatus:     s.raiseForStatus,
+		errorTypes:         s.errorTypes,
+		codecs:             s.codecs,
+	}
+}
+
 // Do sends a session HTTP request and returns a response.
 func (s *Session) Do(req *http.Request) (*Response, error) {
+	return s.do(req, s.options())
+}
+
+func (s *Session) do(req *http.Request, o requestOptions) (*Response, error) {
+	req = s.prepare(req, o)
+	trace, ctx := newConnTrace(req.Context())
+	req = req.WithContext(ctx)
+	var history []*Redirect
+	client := s.redirectClient(s.httpClient(), &history)
+	resp, attempts, err := o.retry.do(func(req *http.Request) (*http.Response, error) {
+		// Each attempt records its own redirects.
+		history = history[:0]
+		return client.Do(req)
+	}, req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := buildResponse(resp, &o)
+	if err != nil {
+		return nil, err
+	}
+	r.attempts = attempts
+	r.trace = trace
+	r.History = history
+	if len(o.expect) > 0 {
+		if !slices.Contains(o.expect, r.StatusCode) {
+			return r, r.statusError()
+		}
+	} else if o.raiseForStatus || r.errorType() != nil {
+		if err := r.RaiseForStatus(); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// prepare returns a copy of req with the default, Session and request headers merged.
+func (s *Session) prepare(req *http.Request, o requestOptions) *http.Request {
 	req = req.Clone(req.Context())
 	header := make(http.Header)
 	for k, v := range defaultHeaders() {
 		header.Set(k, v)
 	}
+	header.Set("Accept-Encoding", acceptEncoding(o.decoders))
 	for k, v := range s.Header {
 		header[k] = v
 	}
@@ -121,11 +254,7 @@ func (s *Session) Do(req *http.Request) (*Response, error) {
 		header[k] = v
 	}
 	req.Header = header
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	return buildResponse(resp)
+	return req
 }
 
 // Get issues a session GET to the specified URL with additional headers.
@@ -135,14 +264,7 @@ func (s *Session) Get(url string, headers H) (*Response, error) {
 
 // GetWithContext issues a session GET to the specified URL with context and additional headers.
 func (s *Session) GetWithContext(ctx context.Context, url string, headers H) (*Response, error) {
-	req, err := newRequest(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-	return s.Do(req)
+	return s.send(ctx, "GET", url, headers, nil)
 }
 
 // Head issues a session HEAD to the specified URL with additional headers.
@@ -152,14 +274,7 @@ func (s *Session) Head(url string, headers H) (*Response, error) {
 
 // HeadWithContext issues a session HEAD to the specified URL with context and additional headers.
 func (s *Session) HeadWithContext(ctx context.Context, url string, headers H) (*Response, error) {
-	req, err := newRequest(ctx, "HEAD", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-	return s.Do(req)
+	return s.send(ctx, "HEAD", url, headers, nil)
 }
 
 // Post issues a session POST to the specified URL with additional headers.
@@ -169,14 +284,51 @@ func (s *Session) Post(url string, headers H, data any) (*Response, error) {
 
 // PostWithContext issues a session POST to the specified URL with context and additional headers.
 func (s *Session) PostWithContext(ctx context.Context, url string, headers H, data any) (*Response, error) {
-	req, err := newRequest(ctx, "POST", url, data)
-	if err != nil {
-		return nil, err
-	}
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-	return s.Do(req)
+	return s.send(ctx, "POST", url, headers, data)
+}
+
+// Put issues a session PUT to the specified URL with additional headers.
+func (s *Session) Put(url string, headers H, data any) (*Response, error) {
+	return s.PutWithContext(context.Background(), url, headers, data)
+}
+
+// PutWithContext issues a session PUT to the specified URL with context and additional headers.
+func (s *Session) PutWithContext(ctx context.Context, url string, headers H, data any) (*Response, error) {
+	return s.send(ctx, "PUT", url, headers, data)
+}
+
+// Patch issues a session PATCH to the specified URL with additional headers.
+func (s *Session) Patch(url string, headers H, data any) (*Response, error) {
+	return s.PatchWithContext(context.Background(), url, headers, data)
+}
+
+// PatchWithContext issues a session PATCH to the specified URL with context and additional headers.
+func (s *Session) PatchWithContext(ctx context.Context, url string, headers H, data any) (*Response, error) {
+	return s.send(ctx, "PATCH", url, headers, data)
+}
+
+// Delete issues a session DELETE to the specified URL with additional headers.
+func (s *Session) Delete(url string, headers H, data any) (*Response, error) {
+	return s.DeleteWithContext(context.Background(), url, headers, data)
+}
+
+// DeleteWithContext issues a session DELETE to the specified URL with context and additional headers.
+func (s *Session) DeleteWithContext(ctx context.Context, url string, headers H, data any) (*Response, error) {
+	return s.send(ctx, "DELETE", url, headers, data)
+}
+
+// Options issues a session OPTIONS to the specified URL with additional headers.
+func (s *Session) Options(url string, headers H) (*Response, error) {
+	return s.OptionsWithContext(context.Background(), url, headers)
+}
+
+// OptionsWithContext issues a session OPTIONS to the specified URL with context and additional headers.
+func (s *Session) OptionsWithContext(ctx context.Context, url string, headers H) (*Response, error) {
+	return s.send(ctx, "OPTIONS", url, headers, nil)
+}
+
+func (s *Session) send(ctx context.Context, method, url string, headers H, data any) (*Response, error) {
+	return s.R().Method(method).URL(url).Headers(headers).Body(data).Do(ctx)
 }
 
 // Upload issues a session POST to the specified URL with a multipart document and additional headers.
@@ -190,15 +342,7 @@ func (s *Session) UploadWithContext(ctx context.Context, url string, headers H,
 	if err != nil {
 		return nil, err
 	}
-	req, err := newRequest(ctx, "POST", url, data)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", contentType)
-	for k, v := range headers {
-		req.Header.Set(k, v)
-	}
-	return s.Do(req)
+	return s.R().Method("POST").URL(url).Header("Content-Type", contentType).Headers(headers).Body(data).Do(ctx)
 }
 
 // KeepAlive repeatedly calls fn with a fixed interval delay between each call.
@@ -224,6 +368,26 @@ func PostWithClient(ctx context.Context, url string, headers H, data any, client
 	return newSession(client).PostWithContext(ctx, url, headers, data)
 }
 
+// PutWithClient issues a PUT to the specified URL with context, headers and client.
+func PutWithClient(ctx context.Context, url string, headers H, data any, client *http.Client) (*Response, error) {
+	return newSession(client).PutWithContext(ctx, url, headers, data)
+}
+
+// PatchWithClient issues a PATCH to the specified URL with context, headers and client.
+func PatchWithClient(ctx context.Context, url string, headers H, data any, client *http.Client) (*Response, error) {
+	return newSession(client).PatchWithContext(ctx, url, headers, data)
+}
+
+// DeleteWithClient issues a DELETE to the specified URL with context, headers and client.
+func DeleteWithClient(ctx context.Context, url string, headers H, data any, client *http.Client) (*Response, error) {
+	return newSession(client).DeleteWithContext(ctx, url, headers, data)
+}
+
+// OptionsWithClient issues an OPTIONS to the specified URL with context, headers and client.
+func OptionsWithClient(ctx context.Context, url string, headers H, client *http.Client) (*Response, error) {
+	return newSession(client).OptionsWithContext(ctx, url, headers)
+}
+
 // UploadWithClient issues a POST to the specified URL with context, a multipart document and client.
 func UploadWithClient(ctx context.Context, url string, headers H, params map[string]string, files []*File, client *http.Client) (*Response, error) {
 	return newSession(client).UploadWithContext(ctx, url, headers, params, files...)
@@ -146,3 +146,81 @@ func TestUpload(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestPutPatchDeleteOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, _ := io.ReadAll(r.Body)
+		fmt.Fprint(w, r.Method, " ", string(c))
+	}))
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		method string
+		fn     func(string, H, any) (*Response, error)
+	}{
+		{"PUT", Put},
+		{"PATCH", Patch},
+		{"DELETE", Delete},
+	} {
+		resp, err := tc.fn(ts.URL, H{"hello": "world"}, url.Values{"test": []string{"test"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Request().Method != tc.method {
+			t.Errorf("expected method %q; got %q", tc.method, resp.Request().Method)
+		}
+		if h := resp.Request().Header.Get("hello"); h != "world" {
+			t.Errorf("expected hello header %q; got %q", "world", h)
+		}
+		if ct := resp.Request().Header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+			t.Errorf("expected Content-Type header %q; got %q", "application/x-www-form-urlencoded", ct)
+		}
+		if s, expect := resp.String(), tc.method+" test=test"; s != expect {
+			t.Errorf("expected response body %q; got %q", expect, s)
+		}
+	}
+
+	resp, err := Options(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Request().Method != "OPTIONS" {
+		t.Errorf("expected method %q; got %q", "OPTIONS", resp.Request().Method)
+	}
+	if s := resp.String(); s != "OPTIONS " {
+		t.Errorf("expected response body %q; got %q", "OPTIONS ", s)
+	}
+}
+
+type wrapper struct{ rt http.RoundTripper }
+
+func (w wrapper) RoundTrip(req *http.Request) (*http.Response, error) { return w.rt.RoundTrip(req) }
+func (w wrapper) Unwrap() http.RoundTripper                           { return w.rt }
+
+func TestSetProxyTransport(t *testing.T) {
+	s := NewSession()
+	if err := s.SetProxy("http://localhost"); err != nil {
+		t.Fatal(err)
+	}
+	if s.client.Transport == http.DefaultTransport {
+		t.Error("expected Session transport is not http.DefaultTransport")
+	}
+
+	tr := new(http.Transport)
+	s.SetClient(&http.Client{Transport: wrapper{wrapper{tr}}})
+	if err := s.SetProxyFromEnvironment(); err != nil {
+		t.Fatal(err)
+	}
+	if tr.Proxy == nil {
+		t.Error("expected proxy set through wrappers")
+	}
+
+	s.SetClient(&http.Client{Transport: RoundTripperFunc(http.DefaultTransport.RoundTrip)})
+	if err := s.SetNoProxy(); err != ErrUnsupportedTransport {
+		t.Errorf("expected ErrUnsupportedTransport; got %v", err)
+	}
+	s.SetClient(&http.Client{Transport: wrapper{}})
+	if err := s.SetNoProxy(); err != ErrUnsupportedTransport {
+		t.Errorf("expected ErrUnsupportedTransport; got %v", err)
+	}
+}
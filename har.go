@@ -0,0 +1,423 @@
+package gohttp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// HARRecorder records the exchanges of a Session as HTTP Archive (HAR) 1.2
+// entries, including each redirect, which can be opened in browser devtools.
+// It is safe for concurrent use.
+type HARRecorder struct {
+	mu      sync.Mutex
+	entries []*harEntry
+}
+
+// NewHARRecorder returns a new empty HARRecorder.
+func NewHARRecorder() *HARRecorder {
+	return new(HARRecorder)
+}
+
+// SetHARRecorder sets default client to record exchanges to rec. Nil rec disables recording.
+func SetHARRecorder(rec *HARRecorder) {
+	defaultSession.SetHARRecorder(rec)
+}
+
+// SetHARRecorder sets Session to record each exchange sent over the network
+// to rec, including request and response bodies. An entry is complete once
+// the response body is read to the end or closed. Nil rec disables recording.
+func (s *Session) SetHARRecorder(rec *HARRecorder) {
+	s.har = rec
+}
+
+// Len returns the number of recorded entries.
+func (rec *HARRecorder) Len() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return len(rec.entries)
+}
+
+// Reset removes all recorded entries.
+func (rec *HARRecorder) Reset() {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.entries = nil
+}
+
+// WriteTo writes the recorded entries to w as a HAR 1.2 JSON document.
+func (rec *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	rec.mu.Lock()
+	var log harLog
+	log.Log.Version = "1.2"
+	log.Log.Creator = harCreator{Name: "gohttp", Version: "1"}
+	log.Log.Entries = make([]harEntry, len(rec.entries))
+	for i, e := range rec.entries {
+		log.Log.Entries[i] = *e
+	}
+	rec.mu.Unlock()
+
+	b, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// Save writes the recorded entries to file as a HAR 1.2 JSON document.
+// The file is replaced only when completely written.
+func (rec *HARRecorder) Save(file string) error {
+	return writeFileAtomic(file, 0600, func(w io.Writer) error {
+		_, err := rec.WriteTo(w)
+		return err
+	})
+}
+
+func (rec *HARRecorder) add(e *harEntry) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.entries = append(rec.entries, e)
+}
+
+// maxHARBody is the maximum number of response body bytes captured in an entry.
+const maxHARBody = 1 << 20
+
+type harTransport struct {
+	rec      *HARRecorder
+	decoders map[string]Decoder
+	rt       http.RoundTripper
+}
+
+func (t *harTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace, ctx := newConnTrace(req.Context())
+	req = req.WithContext(ctx)
+
+	e := &harEntry{
+		StartedDateTime: trace.start.Format(time.RFC3339Nano),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     harCookies(req.Cookies()),
+			Headers:     harHeaders(req.Header),
+			QueryString: harQuery(req.URL.Query()),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Cache: struct{}{},
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			b, _ := io.ReadAll(body)
+			body.Close()
+			e.Request.BodySize = int64(len(b))
+			e.Request.PostData = newHARPostData(b, req.Header.Get("Content-Type"))
+		}
+	} else if req.Body == nil || req.Body == http.NoBody {
+		e.Request.BodySize = 0
+	}
+
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	e.Request.HTTPVersion = resp.Proto
+	_, statusText, _ := strings.Cut(resp.Status, " ")
+	e.Response = harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  statusText,
+		HTTPVersion: resp.Proto,
+		Cookies:     harCookies(resp.Cookies()),
+		Headers:     harHeaders(resp.Header),
+		Content:     harContent{Size: -1, MimeType: resp.Header.Get("Content-Type")},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	trace.mu.Lock()
+	e.ServerIPAddress = trace.remoteAddr
+	trace.mu.Unlock()
+	t.rec.add(e)
+	resp.Body = &harBody{ReadCloser: resp.Body, rec: t.rec, decoders: t.decoders, entry: e, trace: trace, resp: resp}
+	return resp, nil
+}
+
+// Unwrap returns the underlying RoundTripper.
+func (t *harTransport) Unwrap() http.RoundTripper {
+	return t.rt
+}
+
+// harBody captures up to maxHARBody bytes of a response body and completes
+// the entry once it is read to the end or closed.
+type harBody struct {
+	io.ReadCloser
+	rec      *HARRecorder
+	decoders map[string]Decoder
+	entry    *harEntry
+	trace    *connTrace
+	resp     *http.Response
+	n        int64
+	buf      bytes.Buffer
+	once     sync.Once
+}
+
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if b.buf.Len() < maxHARBody {
+		b.buf.Write(p[:min(n, maxHARBody-b.buf.Len())])
+	}
+	if err == io.EOF {
+		b.done()
+	}
+	return n, err
+}
+
+func (b *harBody) Close() error {
+	b.done()
+	return b.ReadCloser.Close()
+}
+
+func (b *harBody) done() {
+	b.once.Do(func() {
+		end := time.Now()
+		body := b.buf.Bytes()
+		truncated := b.n > int64(len(body))
+		var text, encoding, comment string
+		size := int64(-1)
+		switch coded := len(contentCodings(b.resp.Header)) > 0; {
+		case truncated && coded:
+			comment = fmt.Sprintf("content omitted: body exceeds %d bytes", maxHARBody)
+		case truncated:
+			size = b.n
+			text, encoding = harText(body)
+			comment = fmt.Sprintf("content truncated to %d bytes", maxHARBody)
+		default:
+			if r, closers, err := decodeBody(bytes.NewReader(body), b.resp.Header, b.decoders); err == nil {
+				if decoded, err := io.ReadAll(r); err == nil {
+					body = decoded
+				}
+				closeAll(closers)
+			}
+			size = int64(len(body))
+			text, encoding = harText(body)
+		}
+		timings := b.trace.timings(end)
+
+		b.rec.mu.Lock()
+		defer b.rec.mu.Unlock()
+
+		e := b.entry
+		e.Response.BodySize = b.n
+		e.Response.Content.Size = size
+		if size >= 0 {
+			e.Response.Content.Compression = size - b.n
+		}
+		e.Response.Content.Text, e.Response.Content.Encoding = text, encoding
+		e.Response.Content.Comment = comment
+		e.Timings = timings
+		e.Time = timings.total()
+	})
+}
+
+func (t *connTrace) timings(end time.Time) harTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ms := func(d time.Duration) float64 {
+		if d < 0 {
+			return -1
+		}
+		return float64(d) / float64(time.Millisecond)
+	}
+	timings := harTimings{
+		Blocked: ms(span(t.start, t.gotConn)),
+		DNS:     -1,
+		Connect: -1,
+		SSL:     -1,
+		Send:    ms(span(t.gotConn, t.wroteRequest)),
+		Wait:    ms(span(t.wroteRequest, t.firstByte)),
+		Receive: ms(span(t.firstByte, end)),
+	}
+	if !t.reused {
+		timings.DNS = ms(span(t.dnsStart, t.dnsDone))
+		connectDone := t.connectDone
+		if t.tlsDone.After(connectDone) {
+			connectDone = t.tlsDone
+		}
+		timings.Connect = ms(span(t.connectStart, connectDone))
+		timings.SSL = ms(span(t.tlsStart, t.tlsDone))
+		first := t.connectStart
+		if !t.dnsStart.IsZero() {
+			first = t.dnsStart
+		}
+		timings.Blocked = ms(span(t.start, first))
+	}
+	return timings
+}
+
+func harHeaders(header http.Header) []harPair {
+	pairs := []harPair{}
+	for _, k := range slices.Sorted(maps.Keys(header)) {
+		for _, v := range header[k] {
+			pairs = append(pairs, harPair{k, v})
+		}
+	}
+	return pairs
+}
+
+func harQuery(query url.Values) []harPair {
+	pairs := []harPair{}
+	for k, vs := range query {
+		for _, v := range vs {
+			pairs = append(pairs, harPair{k, v})
+		}
+	}
+	slices.SortStableFunc(pairs, func(a, b harPair) int { return strings.Compare(a.Name, b.Name) })
+	return pairs
+}
+
+func harCookies(cookies []*http.Cookie) []harCookie {
+	s := []harCookie{}
+	for _, c := range cookies {
+		hc := harCookie{Name: c.Name, Value: c.Value, Path: c.Path, Domain: c.Domain, HTTPOnly: c.HttpOnly, Secure: c.Secure}
+		if !c.Expires.IsZero() {
+			hc.Expires = c.Expires.Format(time.RFC3339)
+		}
+		s = append(s, hc)
+	}
+	return s
+}
+
+func newHARPostData(b []byte, contentType string) *harPostData {
+	p := &harPostData{MimeType: contentType}
+	if mediatype, _, _ := mime.ParseMediaType(contentType); mediatype == "application/x-www-form-urlencoded" {
+		if values, err := url.ParseQuery(string(b)); err == nil {
+			p.Params = harQuery(values)
+		}
+	}
+	p.Text, _ = harText(b)
+	return p
+}
+
+// harText returns b as text, base64 encoded if it is not valid UTF-8 text.
+func harText(b []byte) (text, encoding string) {
+	if utf8.Valid(b) {
+		return string(b), ""
+	}
+	return base64.StdEncoding.EncodeToString(b), "base64"
+}
+
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+}
+
+type harRequest struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	HTTPVersion string       `json:"httpVersion"`
+	Cookies     []harCookie  `json:"cookies"`
+	Headers     []harPair    `json:"headers"`
+	QueryString []harPair    `json:"queryString"`
+	PostData    *harPostData `json:"postData,omitempty"`
+	HeadersSize int64        `json:"headersSize"`
+	BodySize    int64        `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []harCookie `json:"cookies"`
+	Headers     []harPair   `json:"headers"`
+	Content     harContent  `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int64       `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+type harPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+type harPostData struct {
+	MimeType string    `json:"mimeType"`
+	Params   []harPair `json:"params,omitempty"`
+	Text     string    `json:"text"`
+}
+
+type harContent struct {
+	Size        int64  `json:"size"`
+	Compression int64  `json:"compression,omitempty"`
+	MimeType    string `json:"mimeType"`
+	Text        string `json:"text,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// total returns the sum of the known timings, excluding SSL which is
+// included in Connect.
+func (t harTimings) total() float64 {
+	var total float64
+	for _, v := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if v > 0 {
+			total += v
+		}
+	}
+	return total
+}
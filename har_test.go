@@ -0,0 +1,202 @@
+package gohttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHARRecorder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "1", Path: "/", HttpOnly: true})
+			http.Redirect(w, r, "/home?tab=a", http.StatusFound)
+		case "/home":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("welcome"))
+		case "/binary":
+			w.Write([]byte{0xff, 0xfe})
+		}
+	}))
+	defer ts.Close()
+
+	rec := NewHARRecorder()
+	s := NewSession()
+	s.SetHARRecorder(rec)
+	resp, err := s.Post(ts.URL+"/login", nil, url.Values{"user": {"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := resp.String(); s != "welcome" {
+		t.Fatalf("expected %q; got %q", "welcome", s)
+	}
+	resp, err = s.Get(ts.URL+"/binary", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Bytes()
+	if n := rec.Len(); n != 3 {
+		t.Fatalf("expected 3 entries; got %d", n)
+	}
+
+	file := filepath.Join(t.TempDir(), "session.har")
+	if err := rec.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var har harLog
+	if err := json.Unmarshal(b, &har); err != nil {
+		t.Fatal(err)
+	}
+	if v := har.Log.Version; v != "1.2" {
+		t.Errorf("expected version %q; got %q", "1.2", v)
+	}
+	entries := har.Log.Entries
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries; got %d", len(entries))
+	}
+
+	login := entries[0]
+	if login.Request.Method != "POST" || login.Request.PostData == nil || login.Request.PostData.Text != "user=a" ||
+		len(login.Request.PostData.Params) != 1 || login.Request.PostData.Params[0] != (harPair{"user", "a"}) {
+		t.Errorf("unexpected login request: %+v", login.Request)
+	}
+	if login.Response.Status != http.StatusFound || login.Response.StatusText != "Found" || login.Response.RedirectURL != "/home?tab=a" {
+		t.Errorf("unexpected login response: %+v", login.Response)
+	}
+	if cookies := login.Response.Cookies; len(cookies) != 1 || cookies[0].Name != "session" || !cookies[0].HTTPOnly {
+		t.Errorf("unexpected login cookies: %+v", cookies)
+	}
+	if login.Timings.Connect < 0 || login.Timings.Wait < 0 || login.Time <= 0 {
+		t.Errorf("unexpected login timings: %+v", login.Timings)
+	}
+
+	home := entries[1]
+	if home.Request.Method != "GET" || len(home.Request.QueryString) != 1 || home.Request.QueryString[0] != (harPair{"tab", "a"}) {
+		t.Errorf("unexpected home request: %+v", home.Request)
+	}
+	if cookies := home.Request.Cookies; len(cookies) != 1 || cookies[0] != (harCookie{Name: "session", Value: "1"}) {
+		t.Errorf("unexpected home cookies: %+v", cookies)
+	}
+	if c := home.Response.Content; c.Text != "welcome" || c.Size != 7 || c.MimeType != "text/plain" {
+		t.Errorf("unexpected home content: %+v", c)
+	}
+	if home.Timings.Connect != -1 || home.Timings.DNS != -1 {
+		t.Errorf("expected reused connection; got %+v", home.Timings)
+	}
+
+	if c := entries[2].Response.Content; c.Text != "//4=" || c.Encoding != "base64" {
+		t.Errorf("unexpected binary content: %+v", c)
+	}
+
+	rec.Reset()
+	if n := rec.Len(); n != 0 {
+		t.Errorf("expected no entries; got %d", n)
+	}
+	s.SetHARRecorder(nil)
+	if _, err := s.Get(ts.URL+"/home", nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := rec.Len(); n != 0 {
+		t.Errorf("expected no entries; got %d", n)
+	}
+}
+
+func TestHARRecorderWithDebug(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	rec := NewHARRecorder()
+	s := NewSession()
+	s.SetHARRecorder(rec)
+	s.SetDebug(io.Discard, true, true)
+	for range 2 {
+		resp, err := s.Get(ts.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := resp.String(); s != "ok" {
+			t.Fatalf("expected %q; got %q", "ok", s)
+		}
+	}
+	if n := rec.Len(); n != 2 {
+		t.Fatalf("expected 2 entries; got %d", n)
+	}
+	var buf bytes.Buffer
+	if _, err := rec.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var har harLog
+	if err := json.Unmarshal(buf.Bytes(), &har); err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range har.Log.Entries {
+		if addr := ts.Listener.Addr().String(); e.ServerIPAddress != addr {
+			t.Errorf("#%d: expected server address %q; got %q", i, addr, e.ServerIPAddress)
+		}
+		if e.Response.Content.Text != "ok" {
+			t.Errorf("#%d: expected content %q; got %q", i, "ok", e.Response.Content.Text)
+		}
+	}
+	if timings := har.Log.Entries[1].Timings; timings.Connect != -1 {
+		t.Errorf("expected reused connection; got %+v", timings)
+	}
+}
+
+func TestHARRecorderContent(t *testing.T) {
+	large := strings.Repeat("0", maxHARBody+100)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			w.Write([]byte(large))
+		case "/rot13":
+			w.Header().Set("Content-Encoding", "rot13")
+			w.Write([]byte(strings.Map(rot13, "Hello, world!")))
+		}
+	}))
+	defer ts.Close()
+
+	rec := NewHARRecorder()
+	s := NewSession()
+	s.SetHARRecorder(rec)
+	s.RegisterDecoder("rot13", func(r io.Reader) (io.ReadCloser, error) {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(strings.NewReader(strings.Map(rot13, string(b)))), nil
+	})
+	for _, path := range []string{"/large", "/rot13"} {
+		resp, err := s.Get(ts.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp)
+	}
+	if n := len(rec.entries); n != 2 {
+		t.Fatalf("expected 2 entries; got %d", n)
+	}
+	large0 := rec.entries[0].Response
+	if large0.BodySize != int64(len(large)) {
+		t.Errorf("expected body size %d; got %d", len(large), large0.BodySize)
+	}
+	if n := len(large0.Content.Text); n != maxHARBody || large0.Content.Comment == "" {
+		t.Errorf("expected content truncated to %d bytes with comment; got %d bytes, comment %q", maxHARBody, n, large0.Content.Comment)
+	}
+	if text := rec.entries[1].Response.Content.Text; text != "Hello, world!" {
+		t.Errorf("expected content decoded by Session decoder %q; got %q", "Hello, world!", text)
+	}
+}
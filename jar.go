@@ -0,0 +1,426 @@
+package gohttp
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+var _ http.CookieJar = &Jar{}
+
+// Jar is an http.CookieJar which keeps track of every stored cookie so that
+// they can be saved to and loaded from disk. Cookie matching is delegated to
+// net/http/cookiejar using the public suffix list.
+type Jar struct {
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	entries map[string]entry
+}
+
+// entry is a stored cookie. Zero Expires means a session cookie.
+type entry struct {
+	Name     string        `json:"name"`
+	Value    string        `json:"value"`
+	Domain   string        `json:"domain"`
+	Path     string        `json:"path"`
+	Expires  time.Time     `json:"expires,omitzero"`
+	Secure   bool          `json:"secure,omitempty"`
+	HttpOnly bool          `json:"httpOnly,omitempty"`
+	SameSite http.SameSite `json:"sameSite,omitempty"`
+	HostOnly bool          `json:"hostOnly,omitempty"`
+}
+
+func (e *entry) key() string {
+	return e.Domain + ";" + e.Path + ";" + e.Name
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !e.Expires.After(now)
+}
+
+// NewJar returns a new empty Jar.
+func NewJar() *Jar {
+	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	return &Jar{jar: jar, entries: make(map[string]entry)}
+}
+
+// Cookies implements the Cookies method of the http.CookieJar interface.
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.jar.Cookies(u)
+}
+
+// SetCookies implements the SetCookies method of the http.CookieJar interface.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.jar.SetCookies(u, cookies)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return
+	}
+	host, err := canonicalHost(u.Host)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for _, c := range cookies {
+		domain, hostOnly, ok := cookieDomain(host, c.Domain)
+		if !ok {
+			continue
+		}
+		e := entry{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			SameSite: c.SameSite,
+			HostOnly: hostOnly,
+		}
+		if e.Path == "" || e.Path[0] != '/' {
+			e.Path = defaultPath(u.Path)
+		}
+		switch {
+		case c.MaxAge < 0:
+			delete(j.entries, e.key())
+			continue
+		case c.MaxAge > 0:
+			e.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case !c.Expires.IsZero():
+			if !c.Expires.After(now) {
+				delete(j.entries, e.key())
+				continue
+			}
+			e.Expires = c.Expires
+		}
+		j.entries[e.key()] = e
+	}
+}
+
+// url returns a URL the entry could have been received from.
+func (e *entry) url() *url.URL {
+	host := e.Domain
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	u := &url.URL{Scheme: "http", Host: host, Path: e.Path}
+	if e.Secure {
+		u.Scheme = "https"
+	}
+	return u
+}
+
+// cookie returns the entry as a cookie which is stored the same way
+// when received from url.
+func (e *entry) cookie() *http.Cookie {
+	c := &http.Cookie{
+		Name:     e.Name,
+		Value:    e.Value,
+		Path:     e.Path,
+		Expires:  e.Expires,
+		Secure:   e.Secure,
+		HttpOnly: e.HttpOnly,
+		SameSite: e.SameSite,
+	}
+	if !e.HostOnly {
+		c.Domain = e.Domain
+	}
+	return c
+}
+
+// set stores entries as if they were received from their own domain.
+func (j *Jar) set(entries []entry) {
+	now := time.Now()
+	for _, e := range entries {
+		if e.expired(now) {
+			continue
+		}
+		j.SetCookies(e.url(), []*http.Cookie{e.cookie()})
+	}
+}
+
+// AllCookies returns all unexpired cookies stored in the jar sorted by domain,
+// path and name. Domain of the returned cookies is always set to the domain
+// they are stored for, including host-only cookies.
+func (j *Jar) AllCookies() []*http.Cookie {
+	return j.filter(func(entry) bool { return true })
+}
+
+// CookiesByDomain returns all unexpired cookies stored for domain and its subdomains.
+func (j *Jar) CookiesByDomain(domain string) []*http.Cookie {
+	domain = strings.ToLower(strings.Trim(domain, "."))
+	return j.filter(func(e entry) bool {
+		return e.Domain == domain || strings.HasSuffix(e.Domain, "."+domain)
+	})
+}
+
+func (j *Jar) filter(fn func(entry) bool) []*http.Cookie {
+	entries := j.list()
+	slices.SortFunc(entries, func(a, b entry) int {
+		return cmp.Or(
+			strings.Compare(a.Domain, b.Domain),
+			strings.Compare(a.Path, b.Path),
+			strings.Compare(a.Name, b.Name),
+		)
+	})
+	var cookies []*http.Cookie
+	for _, e := range entries {
+		if fn(e) {
+			c := e.cookie()
+			c.Domain = e.Domain
+			cookies = append(cookies, c)
+		}
+	}
+	return cookies
+}
+
+// DeleteCookie deletes the cookie stored for domain and path with name.
+// It reports whether the cookie was found.
+func (j *Jar) DeleteCookie(domain, path, name string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key := (&entry{Domain: strings.ToLower(strings.Trim(domain, ".")), Path: path, Name: name}).key()
+	e, ok := j.entries[key]
+	if !ok {
+		return false
+	}
+	delete(j.entries, key)
+	c := e.cookie()
+	c.MaxAge = -1
+	j.jar.SetCookies(e.url(), []*http.Cookie{c})
+	return true
+}
+
+// Clear deletes all cookies stored in the jar.
+func (j *Jar) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	j.entries = make(map[string]entry)
+}
+
+// list returns all unexpired entries.
+func (j *Jar) list() []entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	entries := make([]entry, 0, len(j.entries))
+	for k, e := range j.entries {
+		if e.expired(now) {
+			delete(j.entries, k)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// Save saves all unexpired cookies, including session cookies, to file
+// in JSON format. The file is replaced atomically.
+func (j *Jar) Save(file string) error {
+	return writeFileAtomic(file, 0600, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(j.list())
+	})
+}
+
+// Load loads cookies saved by Save from file into the jar.
+// Expired cookies are discarded.
+func (j *Jar) Load(file string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var entries []entry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+	j.set(entries)
+	return nil
+}
+
+// SaveNetscape saves all unexpired cookies to file in Netscape cookies.txt
+// format as used by curl and wget. The file is replaced atomically.
+func (j *Jar) SaveNetscape(file string) error {
+	return writeFileAtomic(file, 0600, func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		fmt.Fprintln(bw, "# Netscape HTTP Cookie File")
+		for _, e := range j.list() {
+			domain, subdomains := e.Domain, "FALSE"
+			if !e.HostOnly {
+				domain, subdomains = "."+domain, "TRUE"
+			}
+			if e.HttpOnly {
+				domain = "#HttpOnly_" + domain
+			}
+			var expires int64
+			if !e.Expires.IsZero() {
+				expires = e.Expires.Unix()
+			}
+			fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+				domain, subdomains, e.Path, netscapeBool(e.Secure), expires, e.Name, e.Value)
+		}
+		return bw.Flush()
+	})
+}
+
+// LoadNetscape loads cookies from file in Netscape cookies.txt format into the jar.
+// Expired cookies are discarded.
+func (j *Jar) LoadNetscape(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var entries []entry
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		var httpOnly bool
+		if after, ok := strings.CutPrefix(line, "#HttpOnly_"); ok {
+			line, httpOnly = after, true
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return fmt.Errorf("%s:%d: malformed cookie line", file, n)
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s:%d: malformed expires: %v", file, n, err)
+		}
+		e := entry{
+			Name:     fields[5],
+			Value:    fields[6],
+			Domain:   strings.ToLower(strings.TrimPrefix(fields[0], ".")),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+			HostOnly: !strings.EqualFold(fields[1], "TRUE"),
+		}
+		if expires > 0 {
+			e.Expires = time.Unix(expires, 0)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	j.set(entries)
+	return nil
+}
+
+func netscapeBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// writeFileAtomic writes file using fn through a temporary file in the same
+// directory which is renamed to file on success. The file is created with
+// perm before umask.
+func writeFileAtomic(file string, perm os.FileMode, fn func(io.Writer) error) (err error) {
+	var f *os.File
+	for {
+		f, err = os.OpenFile(fmt.Sprintf("%s.%d.tmp", file, rand.Uint32()), os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if !os.IsExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if err = fn(f); err != nil {
+		return
+	}
+	if err = f.Sync(); err != nil {
+		return
+	}
+	if err = f.Close(); err != nil {
+		return
+	}
+	return os.Rename(f.Name(), file)
+}
+
+// canonicalHost strips port and trailing dot from host and lowercases it.
+func canonicalHost(host string) (string, error) {
+	if n := strings.Count(host, ":"); n == 1 || n > 1 && host[0] == '[' && strings.Contains(host, "]:") {
+		var err error
+		if host, _, err = net.SplitHostPort(host); err != nil {
+			return "", err
+		}
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return "", errors.New("empty host")
+	}
+	return host, nil
+}
+
+// cookieDomain returns the domain a cookie received from host is stored for
+// and whether it is a host-only cookie, following net/http/cookiejar.
+func cookieDomain(host, domain string) (string, bool, bool) {
+	if domain == "" {
+		return host, true, true
+	}
+	if net.ParseIP(host) != nil {
+		return host, true, host == domain
+	}
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	if domain == "" || domain[0] == '.' || domain[len(domain)-1] == '.' {
+		return "", false, false
+	}
+	if ps := publicsuffix.List.PublicSuffix(domain); ps != "" && !strings.HasSuffix(domain, "."+ps) {
+		return host, true, host == domain
+	}
+	if host != domain && !strings.HasSuffix(host, "."+domain) {
+		return "", false, false
+	}
+	return domain, false, true
+}
+
+// defaultPath returns the directory part of a URL path per RFC 6265 section 5.1.4.
+func defaultPath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+	return path[:i]
+}
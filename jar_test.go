@@ -0,0 +1,186 @@
+package gohttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJar(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s"})
+		http.SetCookie(w, &http.Cookie{Name: "persistent", Value: "p", Path: "/", MaxAge: 3600, HttpOnly: true})
+		http.SetCookie(w, &http.Cookie{Name: "expired", Value: "e", Path: "/", MaxAge: -1})
+		for _, c := range r.Cookies() {
+			w.Header().Add("X-Cookie", c.String())
+		}
+	}))
+	defer ts.Close()
+	tsURL, _ := url.Parse(ts.URL)
+
+	jar := NewJar()
+	s := NewSessionWithJar(jar)
+	s.SetCookie(tsURL, "expired", "e")
+	if _, err := s.Get(ts.URL+"/path/index", nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(jar.list()); n != 2 {
+		t.Fatalf("expected %d stored cookies; got %d", 2, n)
+	}
+
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name string
+		save func(string) error
+		load func(*Jar, string) error
+	}{
+		{"cookies.json", jar.Save, (*Jar).Load},
+		{"cookies.txt", jar.SaveNetscape, (*Jar).LoadNetscape},
+	} {
+		file := filepath.Join(dir, tc.name)
+		if err := tc.save(file); err != nil {
+			t.Fatal(err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("expected no temporary file left; got %d files", len(entries))
+		}
+
+		loaded := NewJar()
+		if err := tc.load(loaded, file); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := NewSessionWithJar(loaded).Get(ts.URL+"/path/index", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := strings.Join(resp.Header.Values("X-Cookie"), "; "); c != "session=s; persistent=p" && c != "persistent=p; session=s" {
+			t.Errorf("%s: expected cookies sent after load; got %q", tc.name, c)
+		}
+		os.Remove(file)
+	}
+
+	file := filepath.Join(dir, "cookies.txt")
+	os.WriteFile(file, []byte(`# Netscape HTTP Cookie File
+.example.com	TRUE	/	TRUE	0	domain	d
+#HttpOnly_www.example.com	FALSE	/	FALSE	`+fmt.Sprint(time.Now().Add(time.Hour).Unix())+`	host	h
+www.example.com	FALSE	/	FALSE	1	expired	e
+`), 0644)
+	loaded := NewJar()
+	if err := loaded.LoadNetscape(file); err != nil {
+		t.Fatal(err)
+	}
+	if c := loaded.Cookies(&url.URL{Scheme: "https", Host: "sub.example.com", Path: "/"}); len(c) != 1 || c[0].Name != "domain" {
+		t.Errorf("expected domain cookie for subdomain; got %v", c)
+	}
+	if c := loaded.Cookies(&url.URL{Scheme: "http", Host: "www.example.com", Path: "/"}); len(c) != 1 || c[0].Name != "host" {
+		t.Errorf("expected host cookie only for insecure request; got %v", c)
+	}
+
+	os.WriteFile(file, []byte("malformed"), 0644)
+	if err := loaded.LoadNetscape(file); err == nil {
+		t.Error("gave nil error; want error")
+	}
+	if err := loaded.Load(file); err == nil {
+		t.Error("gave nil error; want error")
+	}
+	if err := loaded.Save(filepath.Join(dir, "not", "exist")); err == nil {
+		t.Error("gave nil error; want error")
+	}
+	if runtime.GOOS != "windows" {
+		file := filepath.Join(dir, "saved.json")
+		if err := loaded.Save(file); err != nil {
+			t.Fatal(err)
+		}
+		if info, _ := os.Stat(file); info.Mode().Perm() != 0600 {
+			t.Errorf("expected mode %v; got %v", os.FileMode(0600), info.Mode().Perm())
+		}
+	}
+}
+
+func TestJarManagement(t *testing.T) {
+	s := NewSession()
+	jar := s.Jar()
+	if jar == nil {
+		t.Fatal("expected NewSession uses *Jar")
+	}
+
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	s.SetCookies(&url.URL{Scheme: "https", Host: "www.example.com", Path: "/a/b"}, []*http.Cookie{
+		{Name: "host", Value: "1"},
+		{Name: "domain", Value: "2", Domain: ".example.com", Path: "/", Expires: expires, Secure: true, HttpOnly: true},
+	})
+	s.SetCookie(&url.URL{Scheme: "http", Host: "other.org"}, "other", "3")
+
+	all := jar.AllCookies()
+	if len(all) != 3 {
+		t.Fatalf("expected %d cookies; got %d", 3, len(all))
+	}
+	if c := all[0]; c.Name != "domain" || c.Domain != "example.com" || c.Path != "/" ||
+		!c.Expires.Equal(expires) || !c.Secure || !c.HttpOnly {
+		t.Errorf("unexpected cookie attributes: %#v", c)
+	}
+	if c := all[1]; c.Name != "other" || c.Domain != "other.org" {
+		t.Errorf("unexpected cookie: %#v", c)
+	}
+	if c := all[2]; c.Name != "host" || c.Domain != "www.example.com" || c.Path != "/a" {
+		t.Errorf("unexpected cookie: %#v", c)
+	}
+
+	if c := jar.CookiesByDomain("example.com"); len(c) != 2 {
+		t.Errorf("expected %d cookies for example.com; got %d", 2, len(c))
+	}
+	if c := jar.CookiesByDomain("www.example.com"); len(c) != 1 || c[0].Name != "host" {
+		t.Errorf("expected host cookie for www.example.com; got %v", c)
+	}
+
+	if jar.DeleteCookie("example.com", "/", "notexist") {
+		t.Error("expected cookie not found")
+	}
+	if !jar.DeleteCookie(".example.com", "/", "domain") {
+		t.Error("expected cookie deleted")
+	}
+	if c := s.Cookies(&url.URL{Scheme: "https", Host: "www.example.com", Path: "/a/"}); len(c) != 1 || c[0].Name != "host" {
+		t.Errorf("expected deleted cookie not sent; got %v", c)
+	}
+
+	jar.Clear()
+	if c := jar.AllCookies(); len(c) != 0 {
+		t.Errorf("expected no cookies after clear; got %d", len(c))
+	}
+	if c := s.Cookies(&url.URL{Scheme: "https", Host: "www.example.com", Path: "/a/"}); len(c) != 0 {
+		t.Errorf("expected no cookies sent after clear; got %v", c)
+	}
+
+	if NewSessionWithJar(nil).Jar() != nil {
+		t.Error("expected nil Jar")
+	}
+}
+
+func TestJarConcurrentClear(t *testing.T) {
+	jar := NewJar()
+	u, _ := url.Parse("https://example.com/")
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				if i%2 == 0 {
+					jar.SetCookies(u, []*http.Cookie{{Name: "a", Value: "1"}})
+					jar.Clear()
+				} else {
+					jar.Cookies(u)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,73 @@
+package gohttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// GetJSON issues a GET to the specified URL with context and additional
+// headers using s, or default session if s is nil, and decodes the JSON
+// response body into a value of type T. See DoJSON.
+func GetJSON[T any](ctx context.Context, s *Session, url string, headers H) (T, *Response, error) {
+	if s == nil {
+		s = defaultSession
+	}
+	return DoJSON[T](ctx, s.R().URL(url).Headers(headers))
+}
+
+// PostJSON issues a POST to the specified URL with context, additional
+// headers and data using s, or default session if s is nil, and decodes the
+// JSON response body into a value of type T. See DoJSON.
+func PostJSON[T any](ctx context.Context, s *Session, url string, headers H, data any) (T, *Response, error) {
+	if s == nil {
+		s = defaultSession
+	}
+	return DoJSON[T](ctx, s.R().Method("POST").URL(url).Headers(headers).Body(data))
+}
+
+// DoJSON sends the request with context and decodes the JSON response body
+// into a value of type T, streaming from the body. Accept defaults to
+// application/json. A response with an unexpected status code, which is a
+// non-2xx one unless set by Request.Expect, returns a *StatusError, and a
+// response whose media type is neither application/json nor +json returns
+// an error. The request r is not modified. The body of the returned
+// Response is consumed and closed.
+func DoJSON[T any](ctx context.Context, r *Request) (v T, resp *Response, err error) {
+	r = r.clone()
+	if r.header.Get("Accept") == "" {
+		r.Header("Accept", "application/json")
+	}
+	resp, err = r.Stream(true).Do(ctx)
+	if err != nil {
+		if resp != nil {
+			resp.Close()
+		}
+		return
+	}
+	defer resp.Close()
+
+	if len(r.expect) == 0 {
+		if err = resp.RaiseForStatus(); err != nil {
+			return
+		}
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		return
+	}
+	if contentType := resp.Header.Get("Content-Type"); !isJSON(contentType) {
+		err = fmt.Errorf("unexpected Content-Type: %q", contentType)
+		return
+	}
+	err = json.NewDecoder(resp).Decode(&v)
+	return
+}
+
+// isJSON reports whether contentType is a JSON media type.
+func isJSON(contentType string) bool {
+	mediatype, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediatype == "application/json" || strings.HasSuffix(mediatype, "+json"))
+}
@@ -0,0 +1,104 @@
+package gohttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetJSON(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); accept != "application/json" {
+			t.Errorf("expected Accept %q; got %q", "application/json", accept)
+		}
+		switch r.URL.Path {
+		case "/item":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			if r.Method == "POST" {
+				b, _ := io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusCreated)
+				w.Write(b)
+				return
+			}
+			w.Write([]byte(`{"id":1,"name":"a"}`))
+		case "/text":
+			w.Write([]byte("plain"))
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		case "/missing":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"id":0,"name":"missing"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	v, resp, err := GetJSON[item](ctx, nil, ts.URL+"/item", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (item{1, "a"}); v != expect {
+		t.Errorf("expected %v; got %v", expect, v)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d; got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	s := NewSession()
+	p, resp, err := PostJSON[*item](ctx, s, ts.URL+"/item", nil, item{2, "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil || *p != (item{2, "b"}) || resp.StatusCode != http.StatusCreated {
+		t.Errorf("unexpected result: %v, %d", p, resp.StatusCode)
+	}
+
+	m, _, err := DoJSON[map[string]any](ctx, s.R().URL(ts.URL+"/item"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["name"] != "a" {
+		t.Errorf("expected %q; got %v", "a", m["name"])
+	}
+
+	r := s.R().URL(ts.URL + "/missing").Expect(http.StatusNotFound)
+	if v, _, err := DoJSON[item](ctx, r); err != nil || v.Name != "missing" {
+		t.Errorf("expected response with expected status decoded; got %v, %v", v, err)
+	}
+	if r.header.Get("Accept") != "" || r.stream != nil {
+		t.Error("expected DoJSON does not modify request")
+	}
+	if _, _, err := DoJSON[item](ctx, s.R().URL(ts.URL+"/item").Expect(http.StatusCreated)); !errors.As(err, new(*StatusError)) {
+		t.Errorf("expected *StatusError; got %v", err)
+	}
+
+	if _, _, err := GetJSON[item](ctx, s, ts.URL+"/text", nil); err == nil {
+		t.Error("gave nil error; want error")
+	}
+	var se *StatusError
+	if _, resp, err := GetJSON[item](ctx, s, ts.URL+"/notfound", nil); !errors.As(err, &se) || resp == nil {
+		t.Errorf("expected *StatusError; got %v", err)
+	}
+	if v, resp, err := GetJSON[item](ctx, s, ts.URL+"/empty", nil); err != nil || v != (item{}) || resp.StatusCode != http.StatusNoContent {
+		t.Errorf("unexpected result: %v, %v", v, err)
+	}
+
+	ts2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.Write([]byte("{"))
+	}))
+	defer ts2.Close()
+	if _, _, err := GetJSON[item](ctx, s, ts2.URL, nil); err == nil {
+		t.Error("gave nil error; want error")
+	}
+}
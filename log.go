@@ -0,0 +1,291 @@
+package gohttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted replaces the values of sensitive headers, parameters and fields in log records.
+const redacted = "REDACTED"
+
+// defaultLogBody is the default maximum number of body bytes in a log record.
+const defaultLogBody = 4 << 10
+
+var (
+	defaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	defaultRedactParams  = []string{"password", "passwd", "token", "access_token", "refresh_token", "api_key", "apikey", "secret", "client_secret"}
+)
+
+// LogOptions configures structured logging of a Session.
+type LogOptions struct {
+	// Level is the level of records for exchanges. The default is slog.LevelInfo.
+	Level slog.Leveler
+	// ErrorLevel is the level of records for transport errors and 5xx
+	// responses. The default is slog.LevelError.
+	ErrorLevel slog.Leveler
+	// Body includes request and response bodies in records, truncated to
+	// MaxBody bytes. Response bodies with a content coding are omitted.
+	Body bool
+	// MaxBody is the maximum number of body bytes in a record. The default is 4KB.
+	MaxBody int
+	// RedactHeaders lists headers whose values are redacted in addition
+	// to Authorization, Proxy-Authorization, Cookie and Set-Cookie.
+	RedactHeaders []string
+	// RedactParams lists query parameters and form fields whose values are
+	// redacted in addition to common secrets such as password and token.
+	RedactParams []string
+	// RedactFields lists JSON body fields whose values are redacted at any
+	// depth in addition to the ones in RedactParams.
+	RedactFields []string
+}
+
+// SetLogger sets default client to log each exchange to l. Nil l disables logging.
+func SetLogger(l *slog.Logger, opt *LogOptions) {
+	defaultSession.SetLogger(l, opt)
+}
+
+// SetLogger sets Session to log each exchange sent over the network to l
+// with the method, URL, status, duration, sizes and headers, redacting
+// sensitive values. The record is emitted once the response body is read
+// to the end or closed. Nil l disables logging.
+func (s *Session) SetLogger(l *slog.Logger, opt *LogOptions) {
+	if l == nil {
+		s.logger = nil
+		return
+	}
+	if opt == nil {
+		opt = new(LogOptions)
+	}
+	t := &logger{
+		l:          l,
+		level:      slog.LevelInfo,
+		errorLevel: slog.LevelError,
+		body:       opt.Body,
+		maxBody:    opt.MaxBody,
+		headers:    make(map[string]bool),
+		params:     make(map[string]bool),
+		fields:     make(map[string]bool),
+	}
+	if opt.Level != nil {
+		t.level = opt.Level.Level()
+	}
+	if opt.ErrorLevel != nil {
+		t.errorLevel = opt.ErrorLevel.Level()
+	}
+	if t.maxBody <= 0 {
+		t.maxBody = defaultLogBody
+	}
+	for _, k := range slices.Concat(defaultRedactHeaders, opt.RedactHeaders) {
+		t.headers[http.CanonicalHeaderKey(k)] = true
+	}
+	for _, k := range slices.Concat(defaultRedactParams, opt.RedactParams) {
+		t.params[strings.ToLower(k)] = true
+		t.fields[strings.ToLower(k)] = true
+	}
+	for _, k := range opt.RedactFields {
+		t.fields[strings.ToLower(k)] = true
+	}
+	s.logger = t
+}
+
+type logger struct {
+	rt http.RoundTripper
+	l  *slog.Logger
+
+	level      slog.Level
+	errorLevel slog.Level
+	body       bool
+	maxBody    int
+
+	headers map[string]bool
+	params  map[string]bool
+	fields  map[string]bool
+}
+
+func (t *logger) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if !t.l.Enabled(ctx, min(t.level, t.errorLevel)) {
+		return t.rt.RoundTrip(req)
+	}
+	start := time.Now()
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", t.redactURL(req.URL)),
+		slog.Int64("request_size", req.ContentLength),
+		t.headerGroup("request_headers", req.Header),
+	}
+	if t.body && req.Body != nil && req.Body != http.NoBody && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			b, _ := io.ReadAll(io.LimitReader(body, int64(t.maxBody)+1))
+			body.Close()
+			attrs = append(attrs, slog.String("request_body", t.redactBody(b, req.Header.Get("Content-Type"))))
+		}
+	}
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil {
+		attrs = append(attrs, slog.Duration("duration", time.Since(start)), slog.String("error", err.Error()))
+		t.l.LogAttrs(ctx, t.errorLevel, "http request", attrs...)
+		return nil, err
+	}
+	attrs = append(attrs,
+		slog.Int("status", resp.StatusCode),
+		t.headerGroup("response_headers", resp.Header),
+	)
+	level := t.level
+	if resp.StatusCode >= 500 {
+		level = t.errorLevel
+	}
+	body := &logBody{ReadCloser: resp.Body, t: t, req: req, resp: resp, start: start, level: level, attrs: attrs}
+	if t.body && len(contentCodings(resp.Header)) == 0 {
+		body.buf = new(bytes.Buffer)
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// Unwrap returns the underlying RoundTripper.
+func (t *logger) Unwrap() http.RoundTripper {
+	return t.rt
+}
+
+func (t *logger) headerGroup(key string, header http.Header) slog.Attr {
+	var attrs []any
+	for _, k := range slices.Sorted(maps.Keys(header)) {
+		v := strings.Join(header[k], ", ")
+		if t.headers[http.CanonicalHeaderKey(k)] {
+			v = redacted
+		}
+		attrs = append(attrs, slog.String(k, v))
+	}
+	return slog.Group(key, attrs...)
+}
+
+func (t *logger) redactURL(u *url.URL) string {
+	if u.RawQuery == "" && u.User == nil {
+		return u.String()
+	}
+	c := *u
+	if c.User != nil {
+		c.User = url.User(c.User.Username())
+	}
+	if query := c.Query(); t.redactValues(query) {
+		c.RawQuery = query.Encode()
+	}
+	return c.String()
+}
+
+func (t *logger) redactValues(values url.Values) (changed bool) {
+	for k, v := range values {
+		if t.params[strings.ToLower(k)] {
+			for i := range v {
+				v[i] = redacted
+			}
+			changed = true
+		}
+	}
+	return
+}
+
+// redactBody returns b as a string with sensitive form and JSON fields
+// redacted. A JSON body which cannot be parsed, such as a truncated one,
+// is omitted.
+func (t *logger) redactBody(b []byte, contentType string) string {
+	truncated := len(b) > t.maxBody
+	if truncated {
+		b = b[:t.maxBody]
+	}
+	switch {
+	case isJSON(contentType):
+		var v any
+		if err := json.Unmarshal(b, &v); err != nil {
+			return fmt.Sprintf("[%d bytes of JSON omitted]", len(b))
+		}
+		b, _ = json.Marshal(t.redactJSON(v))
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(b))
+		if err != nil {
+			return fmt.Sprintf("[%d bytes of form omitted]", len(b))
+		}
+		if t.redactValues(values) {
+			b = []byte(values.Encode())
+		}
+	}
+	if truncated {
+		return string(b) + "..."
+	}
+	return string(b)
+}
+
+func (t *logger) redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, x := range v {
+			if t.fields[strings.ToLower(k)] {
+				v[k] = redacted
+			} else {
+				v[k] = t.redactJSON(x)
+			}
+		}
+	case []any:
+		for i, x := range v {
+			v[i] = t.redactJSON(x)
+		}
+	}
+	return v
+}
+
+// logBody counts and optionally captures a response body and emits the
+// record of the exchange once it is read to the end or closed.
+type logBody struct {
+	io.ReadCloser
+	t     *logger
+	req   *http.Request
+	resp  *http.Response
+	start time.Time
+	level slog.Level
+	attrs []slog.Attr
+
+	n    int64
+	buf  *bytes.Buffer
+	once sync.Once
+}
+
+func (b *logBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if b.buf != nil && b.buf.Len() <= b.t.maxBody {
+		b.buf.Write(p[:min(n, b.t.maxBody+1-b.buf.Len())])
+	}
+	if err == io.EOF {
+		b.log()
+	}
+	return n, err
+}
+
+func (b *logBody) Close() error {
+	b.log()
+	return b.ReadCloser.Close()
+}
+
+func (b *logBody) log() {
+	b.once.Do(func() {
+		attrs := append(b.attrs,
+			slog.Duration("duration", time.Since(b.start)),
+			slog.Int64("response_size", b.n),
+		)
+		if b.buf != nil {
+			attrs = append(attrs, slog.String("response_body", b.t.redactBody(b.buf.Bytes(), b.resp.Header.Get("Content-Type"))))
+		}
+		b.t.l.LogAttrs(b.req.Context(), b.level, "http request", attrs...)
+	})
+}
@@ -0,0 +1,107 @@
+package gohttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			http.Error(w, "oops", http.StatusInternalServerError)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"user":{"name":"a","password":"p1"},"items":[{"token":"t1"}]}`))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	s := NewSession()
+	s.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})), &LogOptions{
+		Level:         slog.LevelDebug,
+		Body:          true,
+		RedactHeaders: []string{"x-api-key"},
+		RedactParams:  []string{"sig"},
+	})
+	s.Header.Set("Authorization", "Bearer secret")
+	s.Header.Set("X-Api-Key", "secret")
+	resp, err := s.Post(ts.URL+"/login?sig=secret&page=1", nil, url.Values{"user": {"a"}, "password": {"secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Bytes()
+	resp, err = s.Get(ts.URL+"/error", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+
+	if out := buf.String(); strings.Contains(out, "secret") || strings.Contains(out, "p1") || strings.Contains(out, "t1") {
+		t.Errorf("expected secrets redacted; got %s", out)
+	}
+	dec := json.NewDecoder(&buf)
+	var records []map[string]any
+	for {
+		var record map[string]any
+		if err := dec.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records; got %d", len(records))
+	}
+
+	r := records[0]
+	if r["level"] != "DEBUG" || r["method"] != "POST" || r["status"] != 200.0 {
+		t.Errorf("unexpected record: %v", r)
+	}
+	if s, expect := r["url"], ts.URL+"/login?page=1&sig=REDACTED"; s != expect {
+		t.Errorf("expected url %q; got %q", expect, s)
+	}
+	if s, expect := r["request_body"], "password=REDACTED&user=a"; s != expect {
+		t.Errorf("expected request body %q; got %q", expect, s)
+	}
+	if s, expect := r["response_body"], `{"items":[{"token":"REDACTED"}],"user":{"name":"a","password":"REDACTED"}}`; s != expect {
+		t.Errorf("expected response body %q; got %q", expect, s)
+	}
+	if r["request_size"] != 22.0 || r["response_size"] != 62.0 {
+		t.Errorf("unexpected sizes: %v, %v", r["request_size"], r["response_size"])
+	}
+	if _, ok := r["duration"]; !ok {
+		t.Error("expected duration")
+	}
+	reqHeaders, _ := r["request_headers"].(map[string]any)
+	if reqHeaders["Authorization"] != redacted || reqHeaders["X-Api-Key"] != redacted || reqHeaders["Content-Type"] != "application/x-www-form-urlencoded" {
+		t.Errorf("unexpected request headers: %v", reqHeaders)
+	}
+	if respHeaders, _ := r["response_headers"].(map[string]any); respHeaders["Set-Cookie"] != redacted {
+		t.Errorf("unexpected response headers: %v", respHeaders)
+	}
+
+	if r := records[1]; r["level"] != "ERROR" || r["status"] != 500.0 {
+		t.Errorf("unexpected record: %v", r)
+	}
+
+	buf.Reset()
+	s.SetLogger(nil, nil)
+	resp, err = s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Bytes()
+	if buf.Len() != 0 {
+		t.Errorf("expected no record; got %s", buf.String())
+	}
+}
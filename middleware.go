@@ -0,0 +1,114 @@
+package gohttp
+
+import "net/http"
+
+// Middleware wraps the next http.RoundTripper of a Session to intercept
+// every request sent and every response received, including redirects.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter to allow the use of ordinary functions as http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Use appends middlewares to the default session chain.
+func Use(middlewares ...Middleware) {
+	defaultSession.Use(middlewares...)
+}
+
+// OnRequest adds a hook to the default session which is called before each request is sent.
+func OnRequest(fn func(*http.Request) error) {
+	defaultSession.OnRequest(fn)
+}
+
+// OnResponse adds a hook to the default session which is called after each response is received.
+func OnResponse(fn func(*http.Response) error) {
+	defaultSession.OnResponse(fn)
+}
+
+// Use appends middlewares to the Session chain.
+// The first middleware is the outermost one which sees the request first.
+func (s *Session) Use(middlewares ...Middleware) {
+	s.middlewares = append(s.middlewares, middlewares...)
+}
+
+// OnRequest adds a hook which is called with a copy of each request before
+// it is sent. The hook may modify the request. A non-nil error aborts the request.
+func (s *Session) OnRequest(fn func(*http.Request) error) {
+	s.Use(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			if err := fn(req); err != nil {
+				if req.Body != nil {
+					req.Body.Close()
+				}
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	})
+}
+
+// OnResponse adds a hook which is called with each response after it is
+// received. A non-nil error closes the response body and is returned instead.
+func (s *Session) OnResponse(fn func(*http.Response) error) {
+	s.Use(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			if err := fn(resp); err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			return resp, nil
+		})
+	})
+}
+
+// roundTripper builds the Session chain: middlewares, cache, logger,
+// HAR recorder, debugger and the client transport.
+func (s *Session) roundTripper() http.RoundTripper {
+	rt := s.client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if s.debug != nil {
+		d := *s.debug
+		d.rt = rt
+		rt = &d
+	}
+	if s.har != nil {
+		decoders := s.decoders
+		if decoders == nil {
+			decoders = defaultDecoders
+		}
+		rt = &harTransport{s.har, decoders, rt}
+	}
+	if s.logger != nil {
+		l := *s.logger
+		l.rt = rt
+		rt = &l
+	}
+	if s.cache != nil {
+		rt = &cacheTransport{s.cache, rt}
+	}
+	for i := len(s.middlewares) - 1; i >= 0; i-- {
+		rt = s.middlewares[i](rt)
+	}
+	return rt
+}
+
+// httpClient returns the Session client using the Session chain as transport.
+func (s *Session) httpClient() *http.Client {
+	if len(s.middlewares) == 0 && s.debug == nil && s.logger == nil && s.har == nil && s.cache == nil {
+		return s.client
+	}
+	c := *s.client
+	c.Transport = s.roundTripper()
+	return &c
+}
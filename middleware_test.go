@@ -0,0 +1,74 @@
+package gohttp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Server", "test")
+		fmt.Fprint(w, r.Header.Get("Authorization"))
+	}))
+	defer ts.Close()
+
+	var order []string
+	s := NewSession()
+	var buf bytes.Buffer
+	s.SetDebug(&buf, false, false)
+	s.Use(
+		func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, "outer")
+				return next.RoundTrip(req)
+			})
+		},
+		func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, "inner")
+				return next.RoundTrip(req)
+			})
+		},
+	)
+	s.OnRequest(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer token")
+		return nil
+	})
+	var server string
+	s.OnResponse(func(resp *http.Response) error {
+		server = resp.Header.Get("X-Server")
+		return nil
+	})
+	if err := s.SetProxy("http://localhost"); err != nil {
+		t.Fatal(err)
+	}
+	s.SetNoProxy()
+
+	resp, err := s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := resp.String(); s != "Bearer token" {
+		t.Errorf("expected response body %q; got %q", "Bearer token", s)
+	}
+	if s := strings.Join(order, ","); s != "outer,inner" {
+		t.Errorf("expected middleware order %q; got %q", "outer,inner", s)
+	}
+	if server != "test" {
+		t.Errorf("expected X-Server header %q; got %q", "test", server)
+	}
+	if s := buf.String(); !strings.Contains(s, "-> Authorization: Bearer token") {
+		t.Errorf("expected debug output contains request hook header; got %q", s)
+	}
+
+	errHook := errors.New("hook error")
+	s.OnRequest(func(*http.Request) error { return errHook })
+	if _, err := s.Get(ts.URL, nil); !errors.Is(err, errHook) {
+		t.Errorf("expected hook error; got %v", err)
+	}
+}
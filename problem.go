@@ -0,0 +1,127 @@
+package gohttp
+
+import (
+	"encoding/json"
+	"maps"
+	"mime"
+	"strings"
+)
+
+// ProblemDetails is an RFC 9457 problem details object. It is decoded from
+// application/problem+json error responses unless another error type is
+// registered for the response.
+type ProblemDetails struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Extensions holds any other members of the object.
+	Extensions map[string]any `json:"-"`
+}
+
+func (p *ProblemDetails) Error() string {
+	s := p.Title
+	if s == "" {
+		s = p.Type
+	}
+	if p.Detail != "" {
+		if s != "" {
+			s += ": "
+		}
+		s += p.Detail
+	}
+	if s == "" {
+		return "problem details"
+	}
+	return s
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *ProblemDetails) UnmarshalJSON(b []byte) error {
+	type problem ProblemDetails
+	if err := json.Unmarshal(b, (*problem)(p)); err != nil {
+		return err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+	for k, v := range members {
+		switch k {
+		case "type", "title", "status", "detail", "instance":
+			continue
+		}
+		var x any
+		if err := json.Unmarshal(v, &x); err != nil {
+			return err
+		}
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]any)
+		}
+		p.Extensions[k] = x
+	}
+	return nil
+}
+
+// RegisterErrorType registers the error type of non-2xx responses for default session.
+func RegisterErrorType(key string, newErr func() error) {
+	defaultSession.RegisterErrorType(key, newErr)
+}
+
+// RegisterErrorType registers the error type of non-2xx responses from a host
+// or with a media type for Session. A key containing "/" is a media type such
+// as "application/json"; otherwise it is a host name, optionally with port.
+// Host registrations take precedence over media type ones. newErr returns a
+// pointer which the JSON body is decoded into.
+//
+// Non-2xx responses matching a registration are returned along with a
+// *StatusError wrapping the decoded error, whether or not raising for status
+// is enabled. Nil newErr removes the registration.
+func (s *Session) RegisterErrorType(key string, newErr func() error) {
+	key = strings.ToLower(key)
+	errorTypes := maps.Clone(s.errorTypes)
+	if newErr == nil {
+		delete(errorTypes, key)
+	} else {
+		if errorTypes == nil {
+			errorTypes = make(map[string]func() error)
+		}
+		errorTypes[key] = newErr
+	}
+	s.errorTypes = errorTypes
+}
+
+// errorType returns the registered error type for the response, if any.
+func (r *Response) errorType() func() error {
+	if len(r.errorTypes) == 0 {
+		return nil
+	}
+	if req := r.Request(); req != nil {
+		for _, host := range []string{req.URL.Host, req.URL.Hostname()} {
+			if newErr, ok := r.errorTypes[strings.ToLower(host)]; ok {
+				return newErr
+			}
+		}
+	}
+	mediatype, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return r.errorTypes[mediatype]
+}
+
+// decodeError decodes body into the registered error type for the response,
+// or into ProblemDetails for problem details. It returns nil if the body
+// cannot be decoded.
+func (r *Response) decodeError(body []byte) error {
+	newErr := r.errorType()
+	if newErr == nil {
+		if mediatype, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediatype != "application/problem+json" {
+			return nil
+		}
+		newErr = func() error { return new(ProblemDetails) }
+	}
+	err := newErr()
+	if json.Unmarshal(body, err) != nil {
+		return nil
+	}
+	return err
+}
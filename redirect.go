@@ -0,0 +1,106 @@
+package gohttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const defaultMaxRedirects = 10
+
+// ErrTooManyRedirects is returned when a request exceeds the maximum number of redirects.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// Redirect records a redirect response followed to obtain a Response.
+type Redirect struct {
+	// StatusCode is the redirect status code.
+	StatusCode int
+	// URL is the URL of the request which was redirected.
+	URL string
+	// Location is the Location header of the redirect response.
+	Location string
+	// Header is the header of the redirect response.
+	Header http.Header
+	// Cookies are the cookies set by the redirect response.
+	Cookies []*http.Cookie
+}
+
+// RedirectPolicy defines which redirects a Session follows.
+type RedirectPolicy struct {
+	// MaxRedirects is the maximum number of redirects followed. Zero means 10.
+	MaxRedirects int
+	// NoFollow disables following redirects. The redirect response is returned.
+	NoFollow bool
+	// SameHost only follows redirects to the host of the original request.
+	// A redirect to another host is returned as the response.
+	SameHost bool
+	// Check is called before following each redirect with the upcoming
+	// request and the requests made so far, oldest first. If it returns
+	// http.ErrUseLastResponse, the redirect response is returned;
+	// other errors are returned along with no response.
+	Check func(req *http.Request, via []*http.Request) error
+}
+
+// SetRedirectPolicy sets default redirect policy. Nil means the client default.
+func SetRedirectPolicy(p *RedirectPolicy) {
+	defaultSession.SetRedirectPolicy(p)
+}
+
+// SetRedirectPolicy sets Session redirect policy. Nil means the
+// CheckRedirect of the Session client, which follows up to 10 redirects by default.
+func (s *Session) SetRedirectPolicy(p *RedirectPolicy) {
+	s.redirect = p
+}
+
+func (p *RedirectPolicy) check(req *http.Request, via []*http.Request) error {
+	if p.NoFollow {
+		return http.ErrUseLastResponse
+	}
+	n := p.MaxRedirects
+	if n <= 0 {
+		n = defaultMaxRedirects
+	}
+	if len(via) > n {
+		return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, n)
+	}
+	if p.SameHost && req.URL.Host != via[0].URL.Host {
+		return http.ErrUseLastResponse
+	}
+	if p.Check != nil {
+		return p.Check(req, via)
+	}
+	return nil
+}
+
+// redirectClient returns a copy of client applying the Session redirect
+// policy and recording the redirects followed to history.
+func (s *Session) redirectClient(client *http.Client, history *[]*Redirect) *http.Client {
+	check := client.CheckRedirect
+	if s.redirect != nil {
+		check = s.redirect.check
+	} else if check == nil {
+		check = func(req *http.Request, via []*http.Request) error {
+			if len(via) > defaultMaxRedirects {
+				return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, defaultMaxRedirects)
+			}
+			return nil
+		}
+	}
+	c := *client
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := check(req, via); err != nil {
+			return err
+		}
+		if resp := req.Response; resp != nil {
+			*history = append(*history, &Redirect{
+				StatusCode: resp.StatusCode,
+				URL:        via[len(via)-1].URL.String(),
+				Location:   resp.Header.Get("Location"),
+				Header:     resp.Header,
+				Cookies:    resp.Cookies(),
+			})
+		}
+		return nil
+	}
+	return &c
+}
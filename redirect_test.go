@@ -0,0 +1,140 @@
+package gohttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRedirect(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("other"))
+	}))
+	defer other.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "1"})
+			http.Redirect(w, r, "/step", http.StatusFound)
+		case r.URL.Path == "/step":
+			http.Redirect(w, r, "/home", http.StatusSeeOther)
+		case r.URL.Path == "/other":
+			http.Redirect(w, r, other.URL, http.StatusFound)
+		case strings.HasPrefix(r.URL.Path, "/loop/"):
+			n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/loop/"))
+			http.Redirect(w, r, "/loop/"+strconv.Itoa(n+1), http.StatusFound)
+		default:
+			w.Write([]byte("home"))
+		}
+	}))
+	defer ts.Close()
+
+	s := NewSession()
+	resp, err := s.Get(ts.URL+"/login", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := resp.String(); s != "home" {
+		t.Errorf("expected %q; got %q", "home", s)
+	}
+	if n := len(resp.History); n != 2 {
+		t.Fatalf("expected 2 redirects; got %d", n)
+	}
+	if h := resp.History[0]; h.StatusCode != http.StatusFound || h.URL != ts.URL+"/login" || h.Location != "/step" ||
+		len(h.Cookies) != 1 || h.Cookies[0].Name != "session" {
+		t.Errorf("unexpected first redirect: %+v", h)
+	}
+	if h := resp.History[1]; h.StatusCode != http.StatusSeeOther || h.URL != ts.URL+"/step" || h.Location != "/home" {
+		t.Errorf("unexpected second redirect: %+v", h)
+	}
+	if resp, err := s.Get(ts.URL+"/home", nil); err != nil {
+		t.Fatal(err)
+	} else if len(resp.History) != 0 {
+		t.Errorf("expected no redirects; got %d", len(resp.History))
+	}
+
+	if _, err := s.Get(ts.URL+"/loop/0", nil); !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("expected ErrTooManyRedirects; got %v", err)
+	}
+
+	s.SetRedirectPolicy(&RedirectPolicy{NoFollow: true})
+	resp, err = s.Get(ts.URL+"/login", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusFound || len(resp.History) != 0 {
+		t.Errorf("expected unfollowed redirect; got %d with %d redirects", resp.StatusCode, len(resp.History))
+	}
+
+	s.SetRedirectPolicy(&RedirectPolicy{MaxRedirects: 1})
+	if _, err := s.Get(ts.URL+"/login", nil); !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("expected ErrTooManyRedirects; got %v", err)
+	}
+
+	s.SetRedirectPolicy(&RedirectPolicy{SameHost: true})
+	resp, err = s.Get(ts.URL+"/other", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != other.URL {
+		t.Errorf("expected cross-host redirect returned; got %d", resp.StatusCode)
+	}
+
+	errStop := errors.New("stop")
+	var checked []string
+	s.SetRedirectPolicy(&RedirectPolicy{Check: func(req *http.Request, via []*http.Request) error {
+		checked = append(checked, req.URL.Path)
+		if req.URL.Path == "/home" {
+			return errStop
+		}
+		return nil
+	}})
+	if _, err := s.Get(ts.URL+"/login", nil); !errors.Is(err, errStop) {
+		t.Errorf("expected callback error; got %v", err)
+	}
+	if expect := []string{"/step", "/home"}; strings.Join(checked, ",") != strings.Join(expect, ",") {
+		t.Errorf("expected %v; got %v", expect, checked)
+	}
+
+	s.SetRedirectPolicy(nil)
+	if resp, err := s.Get(ts.URL+"/other", nil); err != nil {
+		t.Fatal(err)
+	} else if s := resp.String(); s != "other" || len(resp.History) != 1 {
+		t.Errorf("expected redirect followed; got %q", s)
+	}
+}
+
+func TestRedirectHistoryRetry(t *testing.T) {
+	var n atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			if n.Add(1) == 1 {
+				http.Redirect(w, r, "/fail", http.StatusFound)
+				return
+			}
+			w.Write([]byte("ok"))
+		case "/fail":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	s := NewSession()
+	s.SetRetry(&RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond})
+	resp, err := s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Attempts() != 2 || resp.String() != "ok" {
+		t.Fatalf("expected second attempt to succeed; got %d attempts", resp.Attempts())
+	}
+	if len(resp.History) != 0 {
+		t.Errorf("expected no redirects from failed attempt; got %d", len(resp.History))
+	}
+}
@@ -3,38 +3,257 @@ package gohttp
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"time"
 )
 
-func newRequest(ctx context.Context, method, reqURL string, data any) (*http.Request, error) {
-	var body io.Reader
-	var contentType string
+// Request is a fluent builder for an HTTP request sent through a Session.
+type Request struct {
+	s *Session
+
+	method  string
+	url     string
+	query   url.Values
+	header  http.Header
+	data    any
+	timeout time.Duration
+	expect  []int
+	retry   *RetryPolicy
+	stream  *bool
+	limits  *[2]int64
+
+	err error
+}
+
+// R creates a new Request builder using default session.
+func R() *Request {
+	return defaultSession.R()
+}
+
+// R creates a new Request builder bound to the Session.
+func (s *Session) R() *Request {
+	return &Request{
+		s:      s,
+		method: "GET",
+		query:  make(url.Values),
+		header: make(http.Header),
+	}
+}
+
+// Method sets the request method. The default method is GET.
+func (r *Request) Method(method string) *Request {
+	r.method = strings.ToUpper(method)
+	return r
+}
+
+// URL sets the request URL.
+func (r *Request) URL(url string) *Request {
+	r.url = url
+	return r
+}
+
+// Query adds the values to key in the request URL query string.
+func (r *Request) Query(key string, values ...string) *Request {
+	for _, v := range values {
+		r.query.Add(key, v)
+	}
+	return r
+}
+
+// Header sets the request header entry associated with key to value.
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// Headers sets all the key-value pairs in headers as request headers.
+func (r *Request) Headers(headers H) *Request {
+	for k, v := range headers {
+		r.header.Set(k, v)
+	}
+	return r
+}
+
+// BasicAuth sets the request's Authorization header to use HTTP Basic Authentication.
+func (r *Request) BasicAuth(username, password string) *Request {
+	req := http.Request{Header: make(http.Header)}
+	req.SetBasicAuth(username, password)
+	r.header.Set("Authorization", req.Header.Get("Authorization"))
+	return r
+}
+
+// BearerAuth sets the request's Authorization header to use Bearer token.
+func (r *Request) BearerAuth(token string) *Request {
+	r.header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+// Body sets the request body.
+// Data other than nil and io.Reader is encoded by the codec registered for
+// the Content-Type header or the type of data, and as JSON by default.
+func (r *Request) Body(data any) *Request {
+	r.data = data
+	return r
+}
+
+// JSON sets the request body to the JSON encoding of v by the codec
+// registered for application/json.
+func (r *Request) JSON(v any) *Request {
+	c := lookupCodec(r.s.codecs, "application/json")
+	if c == nil {
+		c = JSONCodec{}
+	}
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, v); err != nil {
+		r.err = err
+		return r
+	}
+	r.data = bytes.NewReader(buf.Bytes())
+	r.header.Set("Content-Type", "application/json")
+	return r
+}
+
+// Form sets the request body to the URL encoding of values.
+func (r *Request) Form(values url.Values) *Request {
+	r.data = values
+	return r
+}
+
+// Timeout sets a time limit for the request, including reading the response body.
+// Zero means no timeout.
+func (r *Request) Timeout(d time.Duration) *Request {
+	r.timeout = d
+	return r
+}
+
+// Expect sets the expected response status codes. Do returns a *StatusError
+// along with the response if the response status code is not one of them.
+func (r *Request) Expect(codes ...int) *Request {
+	r.expect = codes
+	return r
+}
 
+// Retry sets the retry policy for the request, overriding the Session one.
+func (r *Request) Retry(p *RetryPolicy) *Request {
+	r.retry = p
+	return r
+}
+
+// Stream sets whether the response is streamed, overriding the Session setting.
+func (r *Request) Stream(stream bool) *Request {
+	r.stream = &stream
+	return r
+}
+
+// MaxResponseBytes sets the response body size limits, compressed and
+// decompressed, overriding the Session ones. Zero means no limit.
+func (r *Request) MaxResponseBytes(compressed, decompressed int64) *Request {
+	r.limits = &[2]int64{compressed, decompressed}
+	return r
+}
+
+// Do builds the request and sends it using the bound Session.
+func (r *Request) Do(ctx context.Context) (*Response, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var cancel context.CancelFunc
+	if r.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+	}
+	resp, err := r.do(ctx)
+	if resp == nil {
+		if cancel != nil {
+			cancel()
+		}
+		return nil, err
+	}
+	resp.cancel = cancel
+	return resp, err
+}
+
+func (r *Request) do(ctx context.Context) (*Response, error) {
+	req, o, err := r.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.s.do(req, o)
+}
+
+// clone returns a copy of r which can be changed without affecting r.
+func (r *Request) clone() *Request {
+	c := *r
+	c.query = url.Values(http.Header(r.query).Clone())
+	c.header = r.header.Clone()
+	c.expect = slices.Clone(r.expect)
+	return &c
+}
+
+// build returns the request and the Session options overridden by the builder.
+func (r *Request) build(ctx context.Context) (*http.Request, requestOptions, error) {
+	reqURL := r.url
+	if len(r.query) > 0 {
+		u, err := url.Parse(reqURL)
+		if err != nil {
+			return nil, requestOptions{}, err
+		}
+		query := u.Query()
+		for k, v := range r.query {
+			query[k] = append(query[k], v...)
+		}
+		u.RawQuery = query.Encode()
+		reqURL = u.String()
+	}
+
+	req, err := r.s.newRequest(ctx, r.method, reqURL, r.data, r.header.Get("Content-Type"))
+	if err != nil {
+		return nil, requestOptions{}, err
+	}
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+	o := r.s.options()
+	if r.retry != nil {
+		o.retry = r.retry
+	}
+	if r.stream != nil {
+		o.stream = *r.stream
+	}
+	if r.limits != nil {
+		o.maxCompressedBytes, o.maxBytes = r.limits[0], r.limits[1]
+	}
+	o.expect = r.expect
+	return req, o, nil
+}
+
+// newRequest returns a request with data as body. Data other than nil and
+// io.Reader is encoded by the codec chosen by contentType or its type.
+func (s *Session) newRequest(ctx context.Context, method, reqURL string, data any, contentType string) (*http.Request, error) {
+	var body io.Reader
 	switch data := data.(type) {
 	case nil:
 	case io.Reader:
 		body = data
-	case url.Values:
-		body = strings.NewReader(data.Encode())
-		contentType = "application/x-www-form-urlencoded"
 	default:
-		b, err := json.Marshal(data)
-		if err != nil {
+		var c Codec
+		c, contentType = s.bodyCodec(data, contentType)
+		var buf bytes.Buffer
+		if err := c.Encode(&buf, data); err != nil {
 			return nil, err
 		}
-		body = bytes.NewBuffer(b)
-		contentType = "application/json"
+		body = bytes.NewReader(buf.Bytes())
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
 	if err != nil {
 		return nil, err
 	}
-	if contentType != "" {
+	if body != nil && contentType != "" {
 		req.Header.Set("Content-Type", contentType)
 	}
 
@@ -0,0 +1,114 @@
+package gohttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequest(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sleep" {
+			time.Sleep(time.Second)
+		}
+		if r.URL.Path == "/created" {
+			w.WriteHeader(http.StatusCreated)
+		}
+		c, _ := io.ReadAll(r.Body)
+		fmt.Fprint(w, string(c))
+	}))
+	defer ts.Close()
+
+	resp, err := NewSession().R().
+		Method("patch").
+		URL(ts.URL+"/?a=1").
+		Query("b", "2", "3").
+		Header("hello", "world").
+		Headers(H{"another": "header"}).
+		BearerAuth("token").
+		JSON(map[string]string{"test": "test"}).
+		Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Request().Method != "PATCH" {
+		t.Errorf("expected method %q; got %q", "PATCH", resp.Request().Method)
+	}
+	if q := resp.Request().URL.RawQuery; q != "a=1&b=2&b=3" {
+		t.Errorf("expected query %q; got %q", "a=1&b=2&b=3", q)
+	}
+	if h := resp.Request().Header.Get("hello"); h != "world" {
+		t.Errorf("expected hello header %q; got %q", "world", h)
+	}
+	if h := resp.Request().Header.Get("another"); h != "header" {
+		t.Errorf("expected another header %q; got %q", "header", h)
+	}
+	if h := resp.Request().Header.Get("Authorization"); h != "Bearer token" {
+		t.Errorf("expected Authorization header %q; got %q", "Bearer token", h)
+	}
+	if ct := resp.Request().Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected Content-Type header %q; got %q", "application/json", ct)
+	}
+	if s := resp.String(); s != `{"test":"test"}` {
+		t.Errorf("expected response body %q; got %q", `{"test":"test"}`, s)
+	}
+
+	resp, err = R().URL(ts.URL).BasicAuth("user", "pass").Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user, pass, ok := resp.Request().BasicAuth(); !ok || user != "user" || pass != "pass" {
+		t.Errorf("expected basic auth %q:%q; got %q:%q", "user", "pass", user, pass)
+	}
+
+	if _, err := R().URL(ts.URL + "/sleep").Timeout(100 * time.Millisecond).Do(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded error; got %v", err)
+	}
+
+	if resp, err := R().URL(ts.URL + "/created").Expect(http.StatusOK).Do(context.Background()); err == nil {
+		t.Error("gave nil error; want error")
+	} else if resp == nil || resp.StatusCode != http.StatusCreated {
+		t.Error("expected response along with unexpected status error")
+	}
+	if _, err := R().URL(ts.URL+"/created").Expect(http.StatusOK, http.StatusCreated).Do(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := R().JSON(make(chan int)).Do(context.Background()); err == nil {
+		t.Error("gave nil error; want error")
+	}
+}
+
+func TestRequestStream(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Hello, world!")
+	}))
+	defer ts.Close()
+
+	s := NewSession()
+	s.SetStream(true)
+	resp, err := s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Streaming() {
+		t.Error("expected streaming response")
+	}
+	resp.Close()
+
+	resp, err = s.R().URL(ts.URL).Stream(false).Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Streaming() {
+		t.Error("expected buffered response")
+	}
+	if s := resp.String(); s != "Hello, world!" {
+		t.Errorf("expected response body %q; got %q", "Hello, world!", s)
+	}
+}
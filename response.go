@@ -2,20 +2,25 @@ package gohttp
 
 import (
 	"bytes"
-	"compress/flate"
-	"compress/gzip"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
 	"mime"
 	"net/http"
-	"os"
+	"time"
 
 	"golang.org/x/net/html/charset"
 )
 
 var _ io.ReadCloser = &Response{}
 
+// ErrStreaming is returned when the body of a streaming Response is
+// requested as a whole.
+var ErrStreaming = errors.New("response body is streamed and not buffered")
+
 // Response represents the response from an HTTP request.
 type Response struct {
 	resp *http.Response
@@ -27,23 +32,43 @@ type Response struct {
 	Header http.Header
 	// ContentLength records the length of the associated content.
 	ContentLength int64
+	// History records the redirects followed to obtain the response, oldest first.
+	History []*Redirect
+
+	buf     *bytes.Buffer
+	cached  bool
+	err     error
+	closers []io.Closer
+
+	cancel   context.CancelFunc
+	attempts int
+
+	errorTypes map[string]func() error
+	codecs     map[string]Codec
 
-	buf    *bytes.Buffer
-	cached bool
+	trace *connTrace
+	end   time.Time
 }
 
-func buildResponse(resp *http.Response) (*Response, error) {
+func buildResponse(resp *http.Response, o *requestOptions) (*Response, error) {
+	if o == nil {
+		o = new(requestOptions)
+	}
+	decoders := o.decoders
+	if decoders == nil {
+		decoders = defaultDecoders
+	}
 	var reader io.Reader = resp.Body
-	switch resp.Header.Get("Content-Encoding") {
-	case "gzip":
-		var err error
-		reader, err = gzip.NewReader(reader)
-		if err != nil {
-			resp.Body.Close()
-			return nil, err
-		}
-	case "deflate":
-		reader = flate.NewReader(reader)
+	if o.maxCompressedBytes > 0 {
+		reader = &limitReader{reader, o.maxCompressedBytes, &ResponseTooLargeError{o.maxCompressedBytes, true}}
+	}
+	reader, closers, err := decodeBody(reader, resp.Header, decoders)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if o.maxBytes > 0 {
+		reader = &limitReader{reader, o.maxBytes, &ResponseTooLargeError{o.maxBytes, false}}
 	}
 	contentType := resp.Header.Get("Content-Type")
 	mediatype, params, _ := mime.ParseMediaType(contentType)
@@ -54,12 +79,16 @@ func buildResponse(resp *http.Response) (*Response, error) {
 			reader = r
 		case io.EOF:
 		default:
+			closeAll(closers)
 			resp.Body.Close()
 			return nil, err
 		}
 	}
-	buf := new(bytes.Buffer)
-	reader = io.TeeReader(reader, buf)
+	var buf *bytes.Buffer
+	if !o.stream {
+		buf = new(bytes.Buffer)
+		reader = io.TeeReader(reader, buf)
+	}
 	return &Response{
 		resp:          resp,
 		body:          reader,
@@ -67,25 +96,83 @@ func buildResponse(resp *http.Response) (*Response, error) {
 		Header:        resp.Header,
 		ContentLength: resp.ContentLength,
 		buf:           buf,
+		closers:       closers,
+		errorTypes:    o.errorTypes,
+		codecs:        o.codecs,
 	}, nil
 }
 
+// Streaming reports whether the response body is passed through
+// without being buffered.
+func (r *Response) Streaming() bool {
+	return r.buf == nil
+}
+
+// ResponseTooLargeError is returned when reading a response body
+// which exceeds a size limit.
+type ResponseTooLargeError struct {
+	// Limit is the maximum number of bytes allowed.
+	Limit int64
+	// Compressed reports whether the limit applies to the body as
+	// received rather than after decoding content codings.
+	Compressed bool
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	if e.Compressed {
+		return fmt.Sprintf("compressed response body exceeds %d bytes", e.Limit)
+	}
+	return fmt.Sprintf("response body exceeds %d bytes", e.Limit)
+}
+
+// limitReader reads at most n bytes from r and returns err if r has more.
+type limitReader struct {
+	r   io.Reader
+	n   int64
+	err *ResponseTooLargeError
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, l.err
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if l.n -= int64(n); l.n < 0 {
+		return n + int(l.n), l.err
+	}
+	return n, err
+}
+
 // Read reads the response body.
 func (r *Response) Read(p []byte) (int, error) {
 	if r.cached {
 		return 0, errors.New("the entire response body has already been read")
 	}
 	n, err := r.body.Read(p)
+	var tooLarge *ResponseTooLargeError
 	if err == io.EOF {
 		r.cached = true
 		r.Close()
+	} else if errors.As(err, &tooLarge) {
+		r.Close()
 	}
 	return n, err
 }
 
 // Close closes the response body.
 func (r *Response) Close() error {
-	return r.resp.Body.Close()
+	if r.end.IsZero() {
+		r.end = time.Now()
+	}
+	closeAll(r.closers)
+	err := r.resp.Body.Close()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return err
 }
 
 // Raw returns origin *http.Response.
@@ -93,6 +180,19 @@ func (r *Response) Raw() *http.Response {
 	return r.resp
 }
 
+// Timings returns the timing breakdown of the exchange which produced the Response.
+func (r *Response) Timings() Timings {
+	if r.trace == nil {
+		return Timings{}
+	}
+	return r.trace.stats(r.end)
+}
+
+// Attempts returns the number of attempts made to obtain this Response.
+func (r *Response) Attempts() int {
+	return r.attempts
+}
+
 // Request is the request that was sent to obtain this Response.
 func (r *Response) Request() *http.Request {
 	return r.resp.Request
@@ -104,35 +204,97 @@ func (r *Response) Cookies() []*http.Cookie {
 }
 
 // Bytes returns a slice of byte of the response body.
+// It returns nil for a streaming Response or if reading the body fails.
 func (r *Response) Bytes() []byte {
+	b, _ := r.bytes()
+	return b
+}
+
+func (r *Response) bytes() ([]byte, error) {
+	if r.Streaming() {
+		return nil, ErrStreaming
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
 	if r.cached {
-		return r.buf.Bytes()
+		return r.buf.Bytes(), nil
 	}
 	if _, err := io.ReadAll(r); err != nil {
-		return nil
+		r.err = err
+		r.Close()
+		return nil, err
 	}
-	return r.buf.Bytes()
+	return r.buf.Bytes(), nil
 }
 
 // String returns the contents of the response body as a string.
+// It returns empty string for a streaming Response.
 func (r *Response) String() string {
 	return string(r.Bytes())
 }
 
 // JSON parses the response body as JSON-encoded data
 // and stores the result in the value pointed to by data.
+// It returns ErrStreaming for a streaming Response, or the error
+// reading the body, such as a *DecodeError for a corrupted body.
 func (r *Response) JSON(data any) error {
-	return json.Unmarshal(r.Bytes(), data)
+	b, err := r.bytes()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, data)
 }
 
-// Save saves the response data to file. It returns the number
-// of bytes written and an error, if any.
-func (r *Response) Save(file string) (int, error) {
-	f, err := os.Create(file)
+// XML parses the response body as XML-encoded data
+// and stores the result in the value pointed to by data.
+// It returns ErrStreaming for a streaming Response, or the error
+// reading the body.
+func (r *Response) XML(data any) error {
+	b, err := r.bytes()
 	if err != nil {
-		return 0, err
+		return err
 	}
-	defer f.Close()
+	return xml.Unmarshal(b, data)
+}
 
-	return f.Write(r.Bytes())
+// Decode parses the response body with the codec registered for its
+// Content-Type and stores the result in the value pointed to by v.
+// A streaming Response is decoded as it is read.
+func (r *Response) Decode(v any) error {
+	contentType := r.Header.Get("Content-Type")
+	c := lookupCodec(r.codecs, contentType)
+	if c == nil {
+		return fmt.Errorf("no codec for Content-Type %q", contentType)
+	}
+	if r.Streaming() {
+		return c.Decode(r, v)
+	}
+	b, err := r.bytes()
+	if err != nil {
+		return err
+	}
+	return c.Decode(bytes.NewReader(b), v)
+}
+
+// Save saves the response data to file. The data is written to a temporary
+// file in the same directory which replaces file only when complete.
+// It returns the number of bytes written and an error, if any.
+func (r *Response) Save(file string) (n int, err error) {
+	err = writeFileAtomic(file, 0666, func(w io.Writer) error {
+		if r.cached && !r.Streaming() {
+			n, err = w.Write(r.buf.Bytes())
+			return err
+		}
+		written, err := io.Copy(w, r)
+		n = int(written)
+		return err
+	})
+	return
+}
+
+func closeAll(closers []io.Closer) {
+	for _, c := range closers {
+		c.Close()
+	}
 }
@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"compress/flate"
 	"compress/gzip"
+	"context"
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -18,8 +22,18 @@ func (errReader) Read(p []byte) (n int, err error) {
 }
 func (errReader) Close() error { return nil }
 
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
 func TestBytes(t *testing.T) {
-	r, err := buildResponse(&http.Response{Body: errReader(0)})
+	r, err := buildResponse(&http.Response{Body: errReader(0)}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -29,7 +43,7 @@ func TestBytes(t *testing.T) {
 	if _, err := buildResponse(&http.Response{
 		Header: http.Header{"Content-Encoding": []string{"gzip"}},
 		Body:   errReader(0),
-	}); err == nil {
+	}, nil); err == nil {
 		t.Error("gave nil error; want test error")
 	}
 
@@ -40,7 +54,7 @@ func TestBytes(t *testing.T) {
 	r, err = buildResponse(&http.Response{
 		Header: http.Header{"Content-Encoding": []string{"gzip"}},
 		Body:   io.NopCloser(&buf),
-	})
+	}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -57,14 +71,14 @@ func TestBytes(t *testing.T) {
 	r, _ = buildResponse(&http.Response{
 		Header: http.Header{"Content-Encoding": []string{"deflate"}},
 		Body:   io.NopCloser(&buf),
-	})
+	}, nil)
 	if b := r.String(); b != "deflate" {
 		t.Errorf("expected %q; got %q", "deflate", b)
 	}
 }
 
 func TestJSON(t *testing.T) {
-	r, err := buildResponse(&http.Response{Body: io.NopCloser(bytes.NewReader([]byte("-1")))})
+	r, err := buildResponse(&http.Response{Body: io.NopCloser(bytes.NewReader([]byte("-1")))}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -73,7 +87,7 @@ func TestJSON(t *testing.T) {
 		t.Error("gave nil error; want error")
 	}
 
-	r, err = buildResponse(&http.Response{Body: errReader(0)})
+	r, err = buildResponse(&http.Response{Body: errReader(0)}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -83,7 +97,7 @@ func TestJSON(t *testing.T) {
 }
 
 func TestSave(t *testing.T) {
-	r, _ := buildResponse(&http.Response{Body: io.NopCloser(bytes.NewBufferString("test"))})
+	r, _ := buildResponse(&http.Response{Body: io.NopCloser(bytes.NewBufferString("test"))}, nil)
 	if _, err := r.Save(""); err == nil {
 		t.Error("gave nil error; want error")
 	}
@@ -104,4 +118,117 @@ func TestSave(t *testing.T) {
 	if s := string(b); s != "test" {
 		t.Errorf("expected %q; got %q", "test", s)
 	}
+
+	dir := t.TempDir()
+	ref, err := os.Create(filepath.Join(dir, "ref"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref.Close()
+	r, _ = buildResponse(&http.Response{Body: io.NopCloser(bytes.NewBufferString("test"))}, nil)
+	if _, err := r.Save(filepath.Join(dir, "file")); err != nil {
+		t.Fatal(err)
+	}
+	refInfo, _ := os.Stat(ref.Name())
+	info, _ := os.Stat(filepath.Join(dir, "file"))
+	if info.Mode() != refInfo.Mode() {
+		t.Errorf("expected mode %v; got %v", refInfo.Mode(), info.Mode())
+	}
+}
+
+func TestStream(t *testing.T) {
+	r, err := buildResponse(&http.Response{Body: io.NopCloser(bytes.NewBufferString("test"))}, &requestOptions{stream: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Streaming() {
+		t.Fatal("expected streaming response")
+	}
+	if b := r.Bytes(); b != nil {
+		t.Errorf("expected nil bytes; got %q", b)
+	}
+	if err := r.JSON(nil); err != ErrStreaming {
+		t.Errorf("expected ErrStreaming; got %v", err)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); s != "test" {
+		t.Errorf("expected %q; got %q", "test", s)
+	}
+	if r.buf != nil {
+		t.Error("expected body not buffered")
+	}
+
+	r, _ = buildResponse(&http.Response{Body: io.NopCloser(bytes.NewBufferString("test"))}, &requestOptions{stream: true})
+	file := filepath.Join(t.TempDir(), "test")
+	if n, err := r.Save(file); err != nil {
+		t.Fatal(err)
+	} else if n != 4 {
+		t.Errorf("expected %d bytes saved; got %d", 4, n)
+	}
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	bomb := encode(t, strings.Repeat("0", 1<<20), "gzip")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(bomb)
+	}))
+	defer ts.Close()
+
+	s := NewSession()
+	s.SetMaxResponseBytes(0, 1000)
+	resp, err := s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tooLarge *ResponseTooLargeError
+	if err := resp.JSON(new(any)); !errors.As(err, &tooLarge) || tooLarge.Limit != 1000 || tooLarge.Compressed {
+		t.Errorf("expected decompressed *ResponseTooLargeError; got %v", err)
+	}
+	if b := resp.Bytes(); b != nil {
+		t.Errorf("expected nil bytes; got %d bytes", len(b))
+	}
+
+	s.SetMaxResponseBytes(100, 0)
+	resp, err = s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(resp); !errors.As(err, &tooLarge) || !tooLarge.Compressed {
+		t.Errorf("expected compressed *ResponseTooLargeError; got %v", err)
+	}
+
+	resp, err = s.R().URL(ts.URL).MaxResponseBytes(int64(len(bomb)), 1<<20).Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(resp.Bytes()); n != 1<<20 {
+		t.Errorf("expected %d bytes; got %d", 1<<20, n)
+	}
+
+	r, _ := buildResponse(&http.Response{Body: io.NopCloser(strings.NewReader("test"))}, &requestOptions{maxBytes: 4})
+	if s := r.String(); s != "test" {
+		t.Errorf("expected %q; got %q", "test", s)
+	}
+	r, _ = buildResponse(&http.Response{Body: io.NopCloser(strings.NewReader("test"))}, &requestOptions{maxBytes: 3, stream: true})
+	b, err := io.ReadAll(r)
+	if !errors.As(err, &tooLarge) {
+		t.Errorf("expected *ResponseTooLargeError; got %v", err)
+	}
+	if s := string(b); s != "tes" {
+		t.Errorf("expected %q; got %q", "tes", s)
+	}
+	body := &closeRecorder{Reader: bytes.NewReader(bomb)}
+	r, _ = buildResponse(&http.Response{Header: http.Header{"Content-Encoding": {"gzip"}}, Body: body}, &requestOptions{maxCompressedBytes: 100, stream: true})
+	_, err = io.ReadAll(r)
+	var decodeErr *DecodeError
+	if !errors.As(err, &tooLarge) || !tooLarge.Compressed || errors.As(err, &decodeErr) {
+		t.Errorf("expected compressed *ResponseTooLargeError; got %v", err)
+	}
+	if !body.closed {
+		t.Error("expected body closed after compressed limit exceeded")
+	}
 }
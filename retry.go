@@ -0,0 +1,192 @@
+package gohttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 10 * time.Second
+)
+
+// RetryPolicy defines when and how a failed request is sent again.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	// Values less than 2 disable retrying.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry. Zero means 100ms.
+	MinBackoff time.Duration
+	// MaxBackoff is the upper bound of the delay between attempts, including
+	// the one requested by a Retry-After header. Zero means 10s.
+	MaxBackoff time.Duration
+	// ShouldRetry reports whether the request should be retried after an attempt.
+	// If nil, DefaultShouldRetry is used for requests with an idempotent method
+	// or an Idempotency-Key header, and other requests are not retried.
+	ShouldRetry func(*http.Response, error) bool
+	// Backoff returns the delay before the given retry attempt, starting from 1.
+	// If nil, exponential backoff with jitter between MinBackoff and MaxBackoff is used.
+	Backoff func(attempt int) time.Duration
+}
+
+// DefaultShouldRetry retries on network errors, 429 Too Many Requests and 5xx responses.
+func DefaultShouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// ExponentialBackoff returns a backoff function which doubles the delay on
+// each attempt starting from min, capped at max, with random jitter of up
+// to half of the delay.
+func ExponentialBackoff(min, max time.Duration) func(int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := min
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		if half := int64(d / 2); half > 0 {
+			d = time.Duration(half + rand.Int64N(half+1))
+		}
+		return d
+	}
+}
+
+// SetRetry sets default retry policy. Nil means no retry.
+func SetRetry(p *RetryPolicy) {
+	defaultSession.SetRetry(p)
+}
+
+// SetRetry sets Session retry policy. Nil means no retry.
+func (s *Session) SetRetry(p *RetryPolicy) {
+	s.retry = p
+}
+
+func (p *RetryPolicy) enabled() bool {
+	return p != nil && p.MaxAttempts > 1
+}
+
+func (p *RetryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(resp, err)
+	}
+	return idempotent(req) && DefaultShouldRetry(resp, err)
+}
+
+// idempotent reports whether req may be sent more than once without
+// side effects beyond the first one.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+func (p *RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return defaultMaxBackoff
+	}
+	return p.MaxBackoff
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	if p.Backoff != nil {
+		return p.Backoff(attempt)
+	}
+	min := p.MinBackoff
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	return ExponentialBackoff(min, p.maxBackoff())(attempt)
+}
+
+// do sends req using send until it succeeds, ShouldRetry gives up, the
+// attempts are exhausted or the request context is done. It returns the
+// last response or error and the number of attempts made.
+func (p *RetryPolicy) do(send func(*http.Request) (*http.Response, error), req *http.Request) (*http.Response, int, error) {
+	if !p.enabled() {
+		resp, err := send(req)
+		return resp, 1, err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, 0, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(b)), nil }
+	}
+
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, attempt - 1, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+		resp, err := send(r)
+		if attempt >= p.MaxAttempts || !p.shouldRetry(req, resp, err) {
+			return resp, attempt, err
+		}
+
+		delay := p.backoff(attempt)
+		if resp != nil {
+			if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = min(d, p.maxBackoff())
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return resp, attempt, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryAfter parses the Retry-After header value which is either
+// delay seconds or an HTTP date.
+func retryAfter(s string) (time.Duration, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 {
+			return 0, false
+		}
+		return time.Duration(n) * time.Second, true
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
@@ -0,0 +1,160 @@
+package gohttp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	var n atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, _ := io.ReadAll(r.Body)
+		switch n.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			fmt.Fprint(w, string(c))
+		}
+	}))
+	defer ts.Close()
+
+	s := NewSession()
+	s.SetRetry(&RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond})
+	resp, err := s.Post(ts.URL, H{"Idempotency-Key": "1"}, io.MultiReader(bytes.NewBufferString("Hello, "), bytes.NewBufferString("world!")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d; got %d", http.StatusOK, resp.StatusCode)
+	}
+	if n := resp.Attempts(); n != 3 {
+		t.Errorf("expected attempts %d; got %d", 3, n)
+	}
+	if s := resp.String(); s != "Hello, world!" {
+		t.Errorf("expected response body %q; got %q", "Hello, world!", s)
+	}
+
+	n.Store(0)
+	resp, err = s.R().URL(ts.URL).Retry(&RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}).Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected status %d; got %d", http.StatusTooManyRequests, resp.StatusCode)
+	}
+	if n := resp.Attempts(); n != 2 {
+		t.Errorf("expected attempts %d; got %d", 2, n)
+	}
+
+	n.Store(0)
+	s.SetRetry(&RetryPolicy{MaxAttempts: 3, MinBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err = s.GetWithContext(ctx, ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := resp.Attempts(); n != 1 {
+		t.Errorf("expected attempts %d; got %d", 1, n)
+	}
+
+	n.Store(0)
+	s.SetRetry(nil)
+	resp, err = s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d; got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	var n atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	s := NewSession()
+	s.SetRetry(&RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond})
+	resp, err := s.Post(ts.URL, nil, "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := resp.Attempts(); n != 1 {
+		t.Errorf("expected attempts %d; got %d", 1, n)
+	}
+	if n := n.Load(); n != 1 {
+		t.Errorf("expected requests %d; got %d", 1, n)
+	}
+
+	n.Store(0)
+	resp, err = s.R().Method(http.MethodPut).URL(ts.URL).Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := resp.Attempts(); n != 3 {
+		t.Errorf("expected attempts %d; got %d", 3, n)
+	}
+}
+
+func TestRetryAfterMaxBackoff(t *testing.T) {
+	var n atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer ts.Close()
+
+	s := NewSession()
+	s.SetRetry(&RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	start := time.Now()
+	resp, err := s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d; got %d", http.StatusOK, resp.StatusCode)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("expected Retry-After capped at MaxBackoff; took %v", d)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if d, ok := retryAfter("2"); !ok || d != 2*time.Second {
+		t.Errorf("expected %v; got %v", 2*time.Second, d)
+	}
+	if d, ok := retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); !ok || d <= 59*time.Minute {
+		t.Errorf("expected about an hour; got %v", d)
+	}
+	for _, s := range []string{"", "-1", "soon"} {
+		if _, ok := retryAfter(s); ok {
+			t.Errorf("expected invalid Retry-After %q", s)
+		}
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		max *= time.Millisecond
+		if d := backoff(attempt + 1); d < max/2 || d > max {
+			t.Errorf("attempt %d: expected backoff between %v and %v; got %v", attempt+1, max/2, max, d)
+		}
+	}
+}
@@ -3,11 +3,9 @@ package gohttp
 import (
 	"io"
 	"net/http"
-	"net/http/cookiejar"
 	"net/url"
+	"reflect"
 	"time"
-
-	"golang.org/x/net/publicsuffix"
 )
 
 var _ http.CookieJar = &Session{}
@@ -16,45 +14,69 @@ var _ http.CookieJar = &Session{}
 type Session struct {
 	client *http.Client
 	Header http.Header
+
+	retry       *RetryPolicy
+	redirect    *RedirectPolicy
+	debug       *debugger
+	logger      *logger
+	har         *HARRecorder
+	cache       *httpCache
+	stream      bool
+	decoders    map[string]Decoder
+	codecs      map[string]Codec
+	bodyTypes   map[reflect.Type]string
+	middlewares []Middleware
+
+	maxBytes           int64
+	maxCompressedBytes int64
+	raiseForStatus     bool
+	errorTypes         map[string]func() error
+	debugTimings       bool
 }
 
 func newSession(client *http.Client) *Session {
-	return &Session{client, make(http.Header)}
+	return &Session{client: client, Header: make(http.Header)}
 }
 
 // NewSession creates and initializes a new Session using initial contents.
+// Each Session owns a clone of http.DefaultTransport.
 func NewSession() *Session {
-	c := new(http.Client)
-	c.Jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
-	return newSession(c)
+	return newSession(&http.Client{Transport: cloneDefaultTransport(), Jar: NewJar()})
+}
+
+// NewSessionWithJar creates and initializes a new Session using jar as cookie jar.
+func NewSessionWithJar(jar http.CookieJar) *Session {
+	s := NewSession()
+	s.client.Jar = jar
+	return s
 }
 
+// SetDebug sets Session to dump requests and responses to w,
+// optionally including bodies. Nil w disables debugging.
 func (s *Session) SetDebug(w io.Writer, reqBody, respBody bool) {
 	if w != nil {
-		s.client.Transport = &debugger{s.client.Transport, w, reqBody, respBody}
-	} else if t, ok := s.client.Transport.(*debugger); ok {
-		s.client.Transport = t.rt
+		s.debug = &debugger{w: w, reqBody: reqBody, respBody: respBody, timings: s.debugTimings}
+	} else {
+		s.debug = nil
 	}
 }
 
-func (s *Session) setProxy(fn func(*http.Request) (*url.URL, error)) {
-	if s.client.Transport == nil {
-		if t, ok := http.DefaultTransport.(*http.Transport); ok {
-			t.Proxy = fn
-			return
-		}
-	} else {
-		if t, ok := s.client.Transport.(*http.Transport); ok {
-			t.Proxy = fn
-			return
-		} else if t, ok := s.client.Transport.(*debugger); ok {
-			if t, ok := t.rt.(*http.Transport); ok {
-				t.Proxy = fn
-				return
-			}
-		}
+// SetDebugTimings sets whether Session debug output includes the timing
+// breakdown of each exchange.
+func (s *Session) SetDebugTimings(timings bool) {
+	s.debugTimings = timings
+	if s.debug != nil {
+		s.debug.timings = timings
 	}
-	panic("Transport is not *http.Transport type")
+}
+
+func (s *Session) setProxy(fn func(*http.Request) (*url.URL, error)) error {
+	t, err := s.transport()
+	if err != nil {
+		return err
+	}
+	t.Proxy = fn
+	return nil
 }
 
 // SetProxy sets Session client transport proxy.
@@ -63,20 +85,33 @@ func (s *Session) SetProxy(proxy string) error {
 	if err != nil {
 		return err
 	}
-
-	s.setProxy(http.ProxyURL(proxyURL))
-
-	return nil
+	return s.setProxy(http.ProxyURL(proxyURL))
 }
 
 // SetNoProxy sets Session client use no proxy.
-func (s *Session) SetNoProxy() {
-	s.setProxy(nil)
+func (s *Session) SetNoProxy() error {
+	return s.setProxy(nil)
 }
 
 // SetProxyFromEnvironment sets Session client use environment proxy.
-func (s *Session) SetProxyFromEnvironment() {
-	s.setProxy(http.ProxyFromEnvironment)
+func (s *Session) SetProxyFromEnvironment() error {
+	return s.setProxy(http.ProxyFromEnvironment)
+}
+
+// SetStream sets whether Session responses are streamed. A streaming
+// Response passes the body straight through Read without buffering it,
+// so Bytes, String and JSON are not available.
+func (s *Session) SetStream(stream bool) {
+	s.stream = stream
+}
+
+// SetMaxResponseBytes sets the maximum number of bytes of a response body
+// read as received (compressed) and after decoding content codings
+// (decompressed). Reading beyond a limit returns *ResponseTooLargeError
+// and closes the connection. Zero means no limit.
+func (s *Session) SetMaxResponseBytes(compressed, decompressed int64) {
+	s.maxCompressedBytes = compressed
+	s.maxBytes = decompressed
 }
 
 // SetTimeout sets Session client timeout. Zero means no timeout.
@@ -89,6 +124,14 @@ func (s *Session) SetClient(c *http.Client) {
 	s.client = c
 }
 
+// Jar returns the Session cookie jar if it is a *Jar. It returns nil if the
+// Session uses another http.CookieJar, such as one given to NewSessionWithJar,
+// or a client set by SetClient whose Jar is not a *Jar.
+func (s *Session) Jar() *Jar {
+	jar, _ := s.client.Jar.(*Jar)
+	return jar
+}
+
 // Cookies returns the cookies to send in a request for the given URL.
 func (s *Session) Cookies(u *url.URL) []*http.Cookie {
 	return s.client.Jar.Cookies(u)
@@ -0,0 +1,87 @@
+package gohttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxErrorBody is the maximum number of body bytes kept in a StatusError.
+const maxErrorBody = 4 << 10
+
+// StatusError is returned for a response with an unexpected status code.
+type StatusError struct {
+	// StatusCode is the response status code.
+	StatusCode int
+	// Status is the response status line, e.g. "404 Not Found".
+	Status string
+	// Method and URL identify the request.
+	Method string
+	URL    string
+	// Header is the response header.
+	Header http.Header
+	// Body is the beginning of the response body, truncated to 4KB.
+	Body []byte
+	// Err is the response body decoded into the error type registered
+	// with RegisterErrorType, or a *ProblemDetails, if any.
+	Err error
+}
+
+func (e *StatusError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s %s: %s: %v", e.Method, e.URL, e.Status, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %s", e.Method, e.URL, e.Status)
+}
+
+func (e *StatusError) Unwrap() error { return e.Err }
+
+// RaiseForStatus returns a *StatusError if the response status code is not 2xx.
+func (r *Response) RaiseForStatus() error {
+	if r.StatusCode >= 200 && r.StatusCode < 300 {
+		return nil
+	}
+	return r.statusError()
+}
+
+func (r *Response) statusError() *StatusError {
+	e := &StatusError{
+		StatusCode: r.StatusCode,
+		Status:     r.resp.Status,
+		Header:     r.Header,
+	}
+	if e.Status == "" {
+		e.Status = fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode))
+	}
+	if req := r.Request(); req != nil {
+		e.Method = req.Method
+		e.URL = req.URL.String()
+	}
+	var body []byte
+	if r.Streaming() {
+		// Put the bytes back so that the body can still be read in full.
+		body, _ = io.ReadAll(io.LimitReader(r.body, maxErrorBody))
+		r.body = io.MultiReader(bytes.NewReader(body), r.body)
+	} else {
+		body = r.Bytes()
+	}
+	e.Err = r.decodeError(body)
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	e.Body = body
+	return e
+}
+
+// SetRaiseForStatus sets whether default client returns a *StatusError
+// along with the response for non-2xx status codes.
+func SetRaiseForStatus(raise bool) {
+	defaultSession.SetRaiseForStatus(raise)
+}
+
+// SetRaiseForStatus sets whether Session returns a *StatusError along with
+// the response for non-2xx status codes.
+func (s *Session) SetRaiseForStatus(raise bool) {
+	s.raiseForStatus = raise
+}
@@ -0,0 +1,158 @@
+package gohttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestStatusError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte("ok"))
+		case "/large":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(strings.Repeat("x", 10000)))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	s := NewSession()
+	resp, err := s.Get(ts.URL+"/notfound", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var se *StatusError
+	if err := resp.RaiseForStatus(); !errors.As(err, &se) {
+		t.Fatalf("expected *StatusError; got %v", err)
+	}
+	if se.StatusCode != http.StatusNotFound || se.Method != "GET" || se.URL != ts.URL+"/notfound" ||
+		string(se.Body) != "not found\n" || se.Header.Get("Content-Type") == "" {
+		t.Errorf("unexpected status error: %#v", se)
+	}
+	if s, expect := se.Error(), "GET "+ts.URL+"/notfound: 404 Not Found"; s != expect {
+		t.Errorf("expected %q; got %q", expect, s)
+	}
+	if s := resp.String(); s != "not found\n" {
+		t.Errorf("expected body still available; got %q", s)
+	}
+
+	s.SetRaiseForStatus(true)
+	if resp, err := s.Get(ts.URL+"/ok", nil); err != nil {
+		t.Error(err)
+	} else if err := resp.RaiseForStatus(); err != nil {
+		t.Error(err)
+	}
+	resp, err = s.Get(ts.URL+"/large", nil)
+	if !errors.As(err, &se) || se.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected *StatusError; got %v", err)
+	}
+	if resp == nil {
+		t.Fatal("expected response along with status error")
+	}
+	if n := len(se.Body); n != maxErrorBody {
+		t.Errorf("expected body truncated to %d bytes; got %d", maxErrorBody, n)
+	}
+	if n := len(resp.Bytes()); n != 10000 {
+		t.Errorf("expected full body %d bytes; got %d", 10000, n)
+	}
+
+	if _, err := s.R().URL(ts.URL + "/notfound").Expect(http.StatusNotFound).Do(context.Background()); err != nil {
+		t.Errorf("expected Expect overrides raise for status; got %v", err)
+	}
+	if _, err := s.R().URL(ts.URL + "/ok").Expect(http.StatusCreated).Do(context.Background()); !errors.As(err, &se) || se.StatusCode != http.StatusOK {
+		t.Errorf("expected *StatusError; got %v", err)
+	}
+
+	s.SetStream(true)
+	resp, err = s.Get(ts.URL+"/large", nil)
+	if !errors.As(err, &se) || len(se.Body) != maxErrorBody {
+		t.Errorf("expected *StatusError with truncated body; got %v", err)
+	}
+	if b, err := io.ReadAll(resp); err != nil || len(b) != 10000 {
+		t.Errorf("expected full streamed body %d bytes; got %d (%v)", 10000, len(b), err)
+	}
+}
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string { return e.Code + ": " + e.Message }
+
+func TestErrorType(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/problem":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50.","balance":30}`))
+		case "/api":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"invalid","message":"bad input"}`))
+		case "/ok":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer ts.Close()
+
+	s := NewSession()
+	resp, err := s.Get(ts.URL+"/problem", nil)
+	if err != nil {
+		t.Fatalf("expected no error without raise for status; got %v", err)
+	}
+	var problem *ProblemDetails
+	if err := resp.RaiseForStatus(); !errors.As(err, &problem) {
+		t.Fatalf("expected *ProblemDetails; got %v", err)
+	}
+	if problem.Status != http.StatusForbidden || problem.Title != "You do not have enough credit." || problem.Extensions["balance"] != 30.0 {
+		t.Errorf("unexpected problem details: %#v", problem)
+	}
+	if s, expect := problem.Error(), "You do not have enough credit.: Your current balance is 30, but that costs 50."; s != expect {
+		t.Errorf("expected %q; got %q", expect, s)
+	}
+
+	if _, err := s.Get(ts.URL+"/api", nil); err != nil {
+		t.Fatalf("expected no error without registered type; got %v", err)
+	}
+	s.RegisterErrorType("application/json", func() error { return new(apiError) })
+	_, err = s.Get(ts.URL+"/api", nil)
+	var ae *apiError
+	if !errors.As(err, &ae) {
+		t.Fatalf("expected *apiError; got %v", err)
+	}
+	if ae.Code != "invalid" || ae.Message != "bad input" {
+		t.Errorf("unexpected api error: %#v", ae)
+	}
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected *StatusError; got %v", err)
+	}
+	if _, err := s.Get(ts.URL+"/ok", nil); err != nil {
+		t.Error(err)
+	}
+
+	type hostError struct{ apiError }
+	u, _ := url.Parse(ts.URL)
+	s.RegisterErrorType(u.Hostname(), func() error { return new(hostError) })
+	if _, err := s.Get(ts.URL+"/problem", nil); !errors.As(err, new(*hostError)) {
+		t.Errorf("expected host error type to take precedence; got %v", err)
+	}
+
+	s.RegisterErrorType(u.Hostname(), nil)
+	s.RegisterErrorType("application/json", nil)
+	if _, err := s.Get(ts.URL+"/api", nil); err != nil {
+		t.Errorf("expected no error after removing registrations; got %v", err)
+	}
+}
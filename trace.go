@@ -0,0 +1,136 @@
+package gohttp
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// connTrace records the times of the phases of an exchange using httptrace.
+type connTrace struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	reused       bool
+	remoteAddr   string
+}
+
+// newConnTrace returns a connTrace started now and a context recording to it.
+// The phases refer to the last exchange made with the context.
+func newConnTrace(ctx context.Context) (*connTrace, context.Context) {
+	t := &connTrace{start: time.Now()}
+	return t, httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// Each redirect or retry starts a new exchange.
+			t.dnsStart, t.dnsDone = time.Time{}, time.Time{}
+			t.connectStart, t.connectDone = time.Time{}, time.Time{}
+			t.tlsStart, t.tlsDone = time.Time{}, time.Time{}
+			t.gotConn, t.wroteRequest, t.firstByte = time.Time{}, time.Time{}, time.Time{}
+			t.reused, t.remoteAddr = false, ""
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { t.set(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.set(&t.dnsDone) },
+		ConnectStart: func(_, _ string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// Keep the first of parallel dials.
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone:       func(_, _ string, _ error) { t.set(&t.connectDone) },
+		TLSHandshakeStart: func() { t.set(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.set(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.gotConn = time.Now()
+			t.reused = info.Reused
+			// Connections faked by httputil.DumpRequestOut have no address.
+			if info.Conn != nil && info.Conn.RemoteAddr() != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.set(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.set(&t.firstByte) },
+	})
+}
+
+func (t *connTrace) set(p *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*p = time.Now()
+}
+
+// span returns the duration from start to end, or -1 if either is unknown.
+func span(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return -1
+	}
+	return end.Sub(start)
+}
+
+// Timings is the timing breakdown of the exchange which produced a Response.
+// With redirects or retries, the phases refer to the last exchange.
+type Timings struct {
+	// DNS is the duration of the DNS lookup.
+	DNS time.Duration
+	// Connect is the duration of establishing the TCP connection.
+	Connect time.Duration
+	// TLSHandshake is the duration of the TLS handshake.
+	TLSHandshake time.Duration
+	// TimeToFirstByte is the time from the request being written to the
+	// first byte of the response, which is mostly server time.
+	TimeToFirstByte time.Duration
+	// BodyTransfer is the time from the first byte of the response to the
+	// body being read or closed.
+	BodyTransfer time.Duration
+	// Total is the time from sending the request, including redirects and
+	// retries, to the body being read or closed, or to the first byte of
+	// the response if the body is not read yet.
+	Total time.Duration
+	// ConnReused reports whether the connection was reused, in which case
+	// DNS, Connect and TLSHandshake are zero.
+	ConnReused bool
+	// RemoteAddr is the remote address of the connection.
+	RemoteAddr string
+}
+
+func (t *connTrace) stats(end time.Time) Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	d := func(start, end time.Time) time.Duration {
+		return max(span(start, end), 0)
+	}
+	timings := Timings{
+		TimeToFirstByte: d(t.wroteRequest, t.firstByte),
+		ConnReused:      t.reused,
+		RemoteAddr:      t.remoteAddr,
+	}
+	if !t.reused {
+		timings.DNS = d(t.dnsStart, t.dnsDone)
+		timings.Connect = d(t.connectStart, t.connectDone)
+		timings.TLSHandshake = d(t.tlsStart, t.tlsDone)
+	}
+	if end.IsZero() {
+		timings.Total = d(t.start, t.firstByte)
+	} else {
+		timings.BodyTransfer = d(t.firstByte, end)
+		timings.Total = d(t.start, end)
+	}
+	return timings
+}
@@ -0,0 +1,61 @@
+package gohttp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimings(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	s := NewSession()
+	s.SetClient(ts.Client())
+	resp, err := s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timings := resp.Timings()
+	if timings.ConnReused || timings.Connect <= 0 || timings.TLSHandshake <= 0 || timings.RemoteAddr != ts.Listener.Addr().String() {
+		t.Errorf("unexpected timings of new connection: %+v", timings)
+	}
+	if timings.TimeToFirstByte < 20*time.Millisecond || timings.BodyTransfer != 0 || timings.Total < timings.TimeToFirstByte {
+		t.Errorf("unexpected timings before reading body: %+v", timings)
+	}
+	resp.Bytes()
+	if timings := resp.Timings(); timings.BodyTransfer <= 0 || timings.Total < timings.TimeToFirstByte+timings.BodyTransfer {
+		t.Errorf("unexpected timings after reading body: %+v", timings)
+	}
+
+	resp, err = s.Get(ts.URL+"/redirect", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+	if timings := resp.Timings(); !timings.ConnReused || timings.Connect != 0 || timings.TLSHandshake != 0 || timings.TimeToFirstByte < 20*time.Millisecond {
+		t.Errorf("unexpected timings of reused connection: %+v", timings)
+	}
+
+	var buf bytes.Buffer
+	s.SetDebug(&buf, false, false)
+	s.SetDebugTimings(true)
+	resp, err = s.Get(ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+	if out := buf.String(); !strings.Contains(out, "-- dns=") || !strings.Contains(out, "reused=true remote="+ts.Listener.Addr().String()) {
+		t.Errorf("expected debug output contains timings; got %q", out)
+	}
+}
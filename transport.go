@@ -0,0 +1,92 @@
+package gohttp
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrUnsupportedTransport is returned when Session client transport is not
+// *http.Transport and does not unwrap to one.
+var ErrUnsupportedTransport = errors.New("transport is not *http.Transport type")
+
+// cloneDefaultTransport returns a clone of http.DefaultTransport,
+// or nil if it is not *http.Transport.
+func cloneDefaultTransport() http.RoundTripper {
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		return t.Clone()
+	}
+	return nil
+}
+
+// transport returns the *http.Transport of Session client, following
+// wrappers which implement Unwrap() http.RoundTripper. A nil client
+// transport is replaced by a clone of http.DefaultTransport owned by Session
+// in a copy of the client, so the client passed to SetClient is not modified.
+func (s *Session) transport() (*http.Transport, error) {
+	if s.client.Transport == nil {
+		t := cloneDefaultTransport()
+		if t == nil {
+			return nil, ErrUnsupportedTransport
+		}
+		c := *s.client
+		c.Transport = t
+		s.client = &c
+	}
+	rt := s.client.Transport
+	for {
+		switch t := rt.(type) {
+		case *http.Transport:
+			return t, nil
+		case interface{ Unwrap() http.RoundTripper }:
+			rt = t.Unwrap()
+		default:
+			return nil, ErrUnsupportedTransport
+		}
+	}
+}
+
+// ConfigureTransport calls fn with the *http.Transport owned by Session.
+func (s *Session) ConfigureTransport(fn func(*http.Transport)) error {
+	t, err := s.transport()
+	if err != nil {
+		return err
+	}
+	fn(t)
+	return nil
+}
+
+// SetMaxIdleConnsPerHost sets the maximum idle (keep-alive) connections to keep per-host.
+// Zero means http.DefaultMaxIdleConnsPerHost.
+func (s *Session) SetMaxIdleConnsPerHost(n int) error {
+	return s.ConfigureTransport(func(t *http.Transport) { t.MaxIdleConnsPerHost = n })
+}
+
+// SetIdleConnTimeout sets the maximum amount of time an idle (keep-alive) connection
+// will remain idle before closing itself. Zero means no limit.
+func (s *Session) SetIdleConnTimeout(d time.Duration) error {
+	return s.ConfigureTransport(func(t *http.Transport) { t.IdleConnTimeout = d })
+}
+
+// SetTLSHandshakeTimeout sets the maximum amount of time to wait for a TLS handshake.
+// Zero means no timeout.
+func (s *Session) SetTLSHandshakeTimeout(d time.Duration) error {
+	return s.ConfigureTransport(func(t *http.Transport) { t.TLSHandshakeTimeout = d })
+}
+
+// SetResponseHeaderTimeout sets the amount of time to wait for a server's response
+// headers after fully writing the request. Zero means no timeout.
+func (s *Session) SetResponseHeaderTimeout(d time.Duration) error {
+	return s.ConfigureTransport(func(t *http.Transport) { t.ResponseHeaderTimeout = d })
+}
+
+// SetDisableKeepAlives sets whether Session uses a connection to the server only for a single request.
+func (s *Session) SetDisableKeepAlives(disable bool) error {
+	return s.ConfigureTransport(func(t *http.Transport) { t.DisableKeepAlives = disable })
+}
+
+// SetForceAttemptHTTP2 sets whether HTTP/2 is enabled when a non-zero Dial,
+// DialTLS, DialContext func or TLSClientConfig is provided.
+func (s *Session) SetForceAttemptHTTP2(force bool) error {
+	return s.ConfigureTransport(func(t *http.Transport) { t.ForceAttemptHTTP2 = force })
+}
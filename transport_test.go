@@ -0,0 +1,70 @@
+package gohttp
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTransport(t *testing.T) {
+	s1, s2 := NewSession(), NewSession()
+	t1, err := s1.transport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t2, err := s2.transport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if t1 == t2 || t1 == http.DefaultTransport || t2 == http.DefaultTransport {
+		t.Error("expected each Session owns its transport")
+	}
+
+	for _, err := range []error{
+		s1.SetMaxIdleConnsPerHost(10),
+		s1.SetIdleConnTimeout(time.Minute),
+		s1.SetTLSHandshakeTimeout(time.Second),
+		s1.SetResponseHeaderTimeout(2 * time.Second),
+		s1.SetDisableKeepAlives(true),
+		s1.SetForceAttemptHTTP2(false),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if t1.MaxIdleConnsPerHost != 10 ||
+		t1.IdleConnTimeout != time.Minute ||
+		t1.TLSHandshakeTimeout != time.Second ||
+		t1.ResponseHeaderTimeout != 2*time.Second ||
+		!t1.DisableKeepAlives ||
+		t1.ForceAttemptHTTP2 {
+		t.Errorf("transport settings not applied: %+v", t1)
+	}
+	if t2.MaxIdleConnsPerHost == 10 || t2.DisableKeepAlives {
+		t.Error("expected settings do not affect other Session")
+	}
+
+	s1.SetClient(&http.Client{Transport: RoundTripperFunc(http.DefaultTransport.RoundTrip)})
+	if err := s1.SetMaxIdleConnsPerHost(1); err != ErrUnsupportedTransport {
+		t.Errorf("expected ErrUnsupportedTransport; got %v", err)
+	}
+}
+
+func TestTransportSharedClient(t *testing.T) {
+	client := &http.Client{}
+	s := NewSession()
+	s.SetClient(client)
+	if err := s.SetNoProxy(); err != nil {
+		t.Fatal(err)
+	}
+	if client.Transport != nil {
+		t.Errorf("expected client passed to SetClient is not modified; got transport %v", client.Transport)
+	}
+	tr, err := s.transport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.Proxy != nil {
+		t.Error("expected Session transport has no proxy")
+	}
+}